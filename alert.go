@@ -0,0 +1,13 @@
+package prefixed
+
+// alerts reports whether OnAlert is invoked for entries of level.
+func (f *TextFormatter) alerts(level Level) bool {
+	if f.OnAlert == nil {
+		return false
+	}
+	threshold := ErrorLevel
+	if f.AlertLevel != nil {
+		threshold = *f.AlertLevel
+	}
+	return level <= threshold
+}
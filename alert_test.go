@@ -0,0 +1,22 @@
+package prefixed
+
+import "testing"
+
+func TestAlertLevel(t *testing.T) {
+	panicOnly := PanicLevel
+	for _, tt := range []struct {
+		threshold *Level
+		level     Level
+		want      bool
+	}{
+		{nil, ErrorLevel, true},
+		{nil, WarnLevel, false},
+		{&panicOnly, PanicLevel, true},
+		{&panicOnly, FatalLevel, false},
+	} {
+		f := &TextFormatter{OnAlert: func(Level, []byte) {}, AlertLevel: tt.threshold}
+		if got := f.alerts(tt.level); got != tt.want {
+			t.Errorf("AlertLevel %v: alerts(%s) = %v, want %v", tt.threshold, tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package prefixed
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// StripWriter removes ANSI escape sequences from the output written to it
+// unless its destination is a terminal, so a single colored formatter can
+// feed io.MultiWriter(os.Stderr, file) and keep the file plain. Each Write is
+// expected to hold complete escape sequences, as with entries written by
+// logrus.
+type StripWriter struct {
+	w     io.Writer
+	strip bool
+}
+
+// NewStripWriter returns a StripWriter writing to w.
+func NewStripWriter(w io.Writer) *StripWriter {
+	return &StripWriter{w: w, strip: !isTerminal(w)}
+}
+
+func (s *StripWriter) Write(p []byte) (int, error) {
+	if !s.strip {
+		return s.w.Write(p)
+	}
+	if _, err := s.w.Write(StripANSI(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// StripANSI returns b with ANSI escape sequences removed, e.g. to store
+// colored output in files. b is not modified.
+func StripANSI(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != 0x1b {
+			out = append(out, b[i])
+			continue
+		}
+		i = ansiSequenceEnd(b, i)
+	}
+	return out
+}
+
+// hasANSI reports whether s contains an escape character.
+func hasANSI(s string) bool {
+	return strings.IndexByte(s, 0x1b) >= 0
+}
+
+// stripANSIString returns s with ANSI escape sequences removed.
+func stripANSIString(s string) string {
+	if !hasANSI(s) {
+		return s
+	}
+	return string(StripANSI([]byte(s)))
+}
+
+// sanitizeKey strips ANSI escape sequences from a field key unless
+// AllowANSI is set.
+func (f *TextFormatter) sanitizeKey(key string) string {
+	if f.AllowANSI {
+		return key
+	}
+	return stripANSIString(key)
+}
+
+// runeWidth returns the number of columns r occupies on a terminal: two for
+// wide East Asian characters, none for combining marks and other zero-width
+// characters, and one otherwise.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// stringWidth returns the number of columns s occupies on a terminal.
+func stringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// visibleWidth returns the number of columns s occupies on a terminal,
+// ignoring ANSI escape sequences.
+func visibleWidth(s string) int {
+	return stringWidth(stripANSIString(s))
+}
+
+// padRight pads s with spaces to width visible columns.
+func padRight(s string, width int) string {
+	if pad := width - visibleWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// padLeft right-aligns s to width visible columns.
+func padLeft(s string, width int) string {
+	if pad := width - visibleWidth(s); pad > 0 {
+		return strings.Repeat(" ", pad) + s
+	}
+	return s
+}
+
+// ansiSequenceEnd returns the index of the last byte of the escape sequence
+// starting at b[start].
+func ansiSequenceEnd(b []byte, start int) int {
+	i := start + 1
+	if i >= len(b) {
+		return start
+	}
+	switch b[i] {
+	case '[':
+	case ']', 'P', 'X', '^', '_':
+		// String sequence such as an OSC window title or hyperlink,
+		// terminated by BEL or ESC \.
+		for i++; i < len(b); i++ {
+			if b[i] == 0x07 {
+				return i
+			}
+			if b[i] == 0x1b && i+1 < len(b) && b[i+1] == '\\' {
+				return i + 1
+			}
+		}
+		return len(b) - 1
+	default:
+		// Two-byte sequence such as ESC c.
+		return i
+	}
+	// Control sequence: parameter and intermediate bytes followed by a final
+	// byte in the range 0x40-0x7e.
+	for i++; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i
+		}
+	}
+	return len(b) - 1
+}
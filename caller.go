@@ -0,0 +1,188 @@
+package prefixed
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Package path of the formatter, used to skip its own frames when looking up
+// the caller.
+var formatterPackage = reflect.TypeOf(Level(0)).PkgPath()
+
+// packageName extracts the package path from a qualified function name such
+// as github.com/x-cray/logrus-prefixed-formatter.(*TextFormatter).Format.
+func packageName(funcName string) string {
+	pkg, _ := splitFunctionName(funcName)
+	return pkg
+}
+
+// splitFunctionName splits a qualified function name into the package path
+// and the function within the package, e.g. gopkg.in/yaml.v2.(*T).Run into
+// gopkg.in/yaml.v2 and (*T).Run. The runtime escapes dots in the last path
+// element as %2e; unescaped, version suffixes like .v2 stay with the package.
+func splitFunctionName(funcName string) (pkg, fn string) {
+	lastSlash := strings.LastIndex(funcName, "/")
+	dot := strings.Index(funcName[lastSlash+1:], ".")
+	if dot < 0 {
+		return funcName, ""
+	}
+	pkg, fn = funcName[:lastSlash+1+dot], funcName[lastSlash+2+dot:]
+	if strings.Contains(pkg, "%2e") {
+		return strings.Replace(pkg, "%2e", ".", -1), fn
+	}
+	for {
+		i := strings.Index(fn, ".")
+		if i < 0 || !isVersionSuffix(fn[:i]) {
+			return pkg, fn
+		}
+		pkg, fn = pkg+"."+fn[:i], fn[i+1:]
+	}
+}
+
+// isVersionSuffix tells whether s is a gopkg.in style version such as v2.
+func isVersionSuffix(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// trimReceiver removes the receiver type from fn, a function name within its
+// package, e.g. (*T).Run and T.Run both become Run. Closures like Run.func1
+// are kept.
+func trimReceiver(fn string) string {
+	if strings.HasPrefix(fn, "(") {
+		if i := strings.Index(fn, ")."); i >= 0 {
+			return fn[i+2:]
+		}
+		return fn
+	}
+	parts := strings.SplitN(fn, ".", 2)
+	if len(parts) == 2 && !isClosureName(parts[1]) {
+		return parts[1]
+	}
+	return fn
+}
+
+// isClosureName tells whether s starts with the name the compiler gives
+// closures, e.g. func1 in Run.func1.
+func isClosureName(s string) bool {
+	if !strings.HasPrefix(s, "func") || len(s) == len("func") {
+		return false
+	}
+	return s[len("func")] >= '0' && s[len("func")] <= '9'
+}
+
+// reportsCaller tells whether the caller is looked up for entries of level.
+func (f *TextFormatter) reportsCaller(level Level) bool {
+	if !f.ReportCaller {
+		return false
+	}
+	return level <= f.callerLevel()
+}
+
+// callerLevel returns the effective CallerLevel.
+func (f *TextFormatter) callerLevel() Level {
+	if f.CallerLevel == nil {
+		return WarnLevel
+	}
+	return *f.CallerLevel
+}
+
+// findCaller returns the first stack frame outside of logrus and the
+// formatter.
+func findCaller() *runtime.Frame {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		pkg := packageName(frame.Function)
+		if pkg != formatterPackage && !strings.HasSuffix(strings.ToLower(pkg), "sirupsen/logrus") {
+			return &frame
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// callerPrefix derives a prefix from the package of the code that logged
+// entry, e.g. storage for mypkg/storage.
+func callerPrefix(entry *logEntry) string {
+	frame := entry.caller
+	if frame == nil {
+		frame = findCaller()
+	}
+	if frame == nil {
+		return ""
+	}
+	pkg := packageName(frame.Function)
+	return pkg[strings.LastIndex(pkg, "/")+1:]
+}
+
+// CallerPath selects how the file of reported callers is rendered.
+type CallerPath int
+
+const (
+	// CallerPathShort renders the base name of the file, e.g. main.go:42.
+	// This is the default.
+	CallerPathShort CallerPath = iota
+	// CallerPathFull renders the full path of the file.
+	CallerPathFull
+)
+
+// FunctionTrim selects the parts removed from caller function names, e.g.
+// TrimPackagePath|TrimReceiver.
+type FunctionTrim int
+
+const (
+	// TrimPackagePath removes the path of the package, keeping its name.
+	TrimPackagePath FunctionTrim = 1 << iota
+	// TrimReceiver removes the receiver type of methods.
+	TrimReceiver
+)
+
+// functionName renders the function of frame trimmed according to the
+// formatter options, e.g. github.com/a/b/pkg.(*T).Run becomes pkg.Run with
+// TrimPackagePath|TrimReceiver.
+func (f *TextFormatter) functionName(frame *runtime.Frame) string {
+	pkg, fn := splitFunctionName(frame.Function)
+	lastSlash := strings.LastIndex(pkg, "/")
+	path, name := pkg[:lastSlash+1], pkg[lastSlash+1:]
+
+	switch {
+	case f.CallerFunctionTrim&TrimPackagePath != 0:
+		path = ""
+	case f.CallerFunctionSegments > 0:
+		segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+		if len(segments) > f.CallerFunctionSegments {
+			path = strings.Join(segments[len(segments)-f.CallerFunctionSegments:], "/") + "/"
+		}
+	}
+
+	if f.CallerFunctionTrim&TrimReceiver != 0 {
+		fn = trimReceiver(fn)
+	}
+	if fn == "" {
+		return path + name
+	}
+	return path + name + "." + fn
+}
+
+// callerText renders the file and line of frame.
+func (f *TextFormatter) callerText(frame *runtime.Frame) string {
+	file := frame.File
+	if f.CallerPath == CallerPathShort {
+		file = filepath.Base(file)
+	}
+	return fmt.Sprintf("%s:%d", file, frame.Line)
+}
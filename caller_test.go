@@ -0,0 +1,22 @@
+package prefixed
+
+import "testing"
+
+func TestCallerLevel(t *testing.T) {
+	panicOnly := PanicLevel
+	for _, tt := range []struct {
+		threshold *Level
+		level     Level
+		want      bool
+	}{
+		{nil, WarnLevel, true},
+		{nil, InfoLevel, false},
+		{&panicOnly, PanicLevel, true},
+		{&panicOnly, ErrorLevel, false},
+	} {
+		f := &TextFormatter{ReportCaller: true, CallerLevel: tt.threshold}
+		if got := f.reportsCaller(tt.level); got != tt.want {
+			t.Errorf("CallerLevel %v: reportsCaller(%s) = %v, want %v", tt.threshold, tt.level, got, tt.want)
+		}
+	}
+}
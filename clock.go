@@ -0,0 +1,106 @@
+package prefixed
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Time relative timestamps are measured from. It starts out as the time the
+// package was initialized, i.e. roughly process start.
+var baseTimestamp atomic.Value
+
+func init() {
+	baseTimestamp.Store(time.Now())
+}
+
+// BaseTimestamp returns the time relative timestamps are measured from.
+func BaseTimestamp() time.Time {
+	return baseTimestamp.Load().(time.Time)
+}
+
+// SetBaseTimestamp sets the time relative timestamps are measured from, e.g.
+// the creation time of a logger.
+func SetBaseTimestamp(t time.Time) {
+	baseTimestamp.Store(t)
+}
+
+// ResetBaseTimestamp restarts relative timestamps from the current time.
+func ResetBaseTimestamp() {
+	SetBaseTimestamp(time.Now())
+}
+
+// fieldTime interprets the value of TimeField as a time: a time.Time, an
+// RFC 3339 string or Unix seconds.
+func fieldTime(value interface{}) (time.Time, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return value, true
+	case *time.Time:
+		if value != nil {
+			return *value, true
+		}
+	case int64:
+		return time.Unix(value, 0), true
+	case int:
+		return time.Unix(int64(value), 0), true
+	case float64:
+		return time.Unix(0, int64(value*float64(time.Second))), true
+	default:
+		return parseJSONTime(value)
+	}
+	return time.Time{}, false
+}
+
+// Clock provides the current time to the formatter. Replacing it allows tests
+// and replay tools to produce byte-identical output.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface, e.g. to freeze time in
+// golden-file tests:
+//
+//	f.Clock = prefixed.ClockFunc(func() time.Time { return fixed })
+type ClockFunc func() time.Time
+
+// Now returns c().
+func (c ClockFunc) Now() time.Time {
+	return c()
+}
+
+func (f *TextFormatter) now() time.Time {
+	if f.Clock == nil {
+		return time.Now()
+	}
+	return f.Clock.Now()
+}
+
+// BaseTimestamp returns the time relative timestamps of the formatter are
+// measured from. Unless set on the formatter, it is the package-wide base.
+func (f *TextFormatter) BaseTimestamp() time.Time {
+	if t, ok := f.baseTimestamp.Load().(time.Time); ok {
+		return t
+	}
+	return BaseTimestamp()
+}
+
+// SetBaseTimestamp sets the time relative timestamps of the formatter are
+// measured from, independently of other formatters.
+func (f *TextFormatter) SetBaseTimestamp(t time.Time) {
+	f.baseTimestamp.Store(t)
+}
+
+// ResetBaseTimestamp restarts relative timestamps of the formatter from the
+// current time of its Clock.
+func (f *TextFormatter) ResetBaseTimestamp() {
+	f.SetBaseTimestamp(f.now())
+}
+
+// elapsed returns the time shown by relative timestamps.
+func (f *TextFormatter) elapsed() time.Duration {
+	base := f.BaseTimestamp()
+	if f.Since != nil {
+		return f.Since(base)
+	}
+	return f.now().Sub(base)
+}
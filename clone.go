@@ -0,0 +1,177 @@
+package prefixed
+
+import "time"
+
+// Clone returns a copy of the formatter with the same options and color
+// scheme, but its own terminal detection and level counters, e.g. to derive
+// a no-color variant for a file from a formatter configured for a terminal.
+// The Encoder, if any, is shared with the copy.
+func (f *TextFormatter) Clone() *TextFormatter {
+	clone := &TextFormatter{
+		ForceColors:         f.ForceColors,
+		DisableColors:       f.DisableColors,
+		DisableTimestamp:    f.DisableTimestamp,
+		ShortTimestamp:      f.ShortTimestamp,
+		HumanShortTimestamp: f.HumanShortTimestamp,
+		TimestampFormat:     f.TimestampFormat,
+		TimestampMillis:     f.TimestampMillis,
+		TimestampUTC:        f.TimestampUTC,
+		TimestampEpoch:      f.TimestampEpoch,
+		TimeField:           f.TimeField,
+		TimeFieldFormat:     f.TimeFieldFormat,
+		TimeFieldLocation:   f.TimeFieldLocation,
+		UnitConventions:     f.UnitConventions,
+		CompactMode:         f.CompactMode,
+		DisableSorting:      f.DisableSorting,
+		SortingFunc:         f.SortingFunc,
+		SpacePadding:        f.SpacePadding,
+		SpacePaddingAuto:    f.SpacePaddingAuto,
+		DisableQuoting:      f.DisableQuoting,
+		StrictLogfmt:        f.StrictLogfmt,
+		ErrorTree:           f.ErrorTree,
+		FieldMergePolicy:    f.FieldMergePolicy,
+		MinLevel:            f.MinLevel,
+		MaxLineLength:       f.MaxLineLength,
+		NormalizeUnicode:    f.NormalizeUnicode,
+		AllowANSI:           f.AllowANSI,
+		SyslogPriority:      f.SyslogPriority,
+		SyslogFacility:      f.SyslogFacility,
+		NumericLevel:        f.NumericLevel,
+		EmptyMessage:        f.EmptyMessage,
+		EmptyMessageText:    f.EmptyMessageText,
+		WarnLabel:           f.WarnLabel,
+		LevelPadding:        f.LevelPadding,
+		ReportCaller:        f.ReportCaller,
+		CallerLevel:         f.CallerLevel,
+		CallerPath:          f.CallerPath,
+		EntryIDs:            f.EntryIDs,
+		OnEntryID:           f.OnEntryID,
+		OnAlert:             f.OnAlert,
+		OnFormat:            f.OnFormat,
+		AlertLevel:          f.AlertLevel,
+		LineEnding:          f.LineEnding,
+		CrashSummary:        f.CrashSummary,
+		ErrorDetails:        f.ErrorDetails,
+		DumpRate:            f.DumpRate,
+		DumpBurst:           f.DumpBurst,
+		Clock:               f.Clock,
+		Since:               f.Since,
+		AutoPrefixColors:    f.AutoPrefixColors,
+		ShowGoroutineID:     f.ShowGoroutineID,
+		GoroutineIDFunc:     f.GoroutineIDFunc,
+		ProcessMetadata:     f.ProcessMetadata,
+		ProcessExe:          f.ProcessExe,
+		PrefixFromCaller:    f.PrefixFromCaller,
+		InlineFieldLimit:    f.InlineFieldLimit,
+		PrefixPadding:       f.PrefixPadding,
+		MaxAutoPadding:      f.MaxAutoPadding,
+		MaxPrefixLength:     f.MaxPrefixLength,
+		PrefixDelimiter:     f.PrefixDelimiter,
+		PrefixDepth:         f.PrefixDepth,
+		WatermarkEvery:      f.WatermarkEvery,
+		WatermarkInterval:   f.WatermarkInterval,
+		CLIMode:             f.CLIMode,
+		WrapLines:           f.WrapLines,
+		WrapWidth:           f.WrapWidth,
+		LayoutTemplate:      f.LayoutTemplate,
+		Encoder:             f.Encoder,
+
+		CallerFunction:         f.CallerFunction,
+		CallerFunctionTrim:     f.CallerFunctionTrim,
+		CallerFunctionSegments: f.CallerFunctionSegments,
+		DisablePanicRecovery:   f.DisablePanicRecovery,
+		TimestampPrecision:     f.TimestampPrecision,
+		ShortTimestampUnit:     f.ShortTimestampUnit,
+		PrefixSyntax:           f.PrefixSyntax,
+		OnError:                f.OnError,
+	}
+
+	if f.OnlyPrefixes != nil {
+		clone.OnlyPrefixes = append([]string(nil), f.OnlyPrefixes...)
+	}
+
+	if f.ExcludePrefixes != nil {
+		clone.ExcludePrefixes = append([]string(nil), f.ExcludePrefixes...)
+	}
+
+	if f.ContextExtractors != nil {
+		clone.ContextExtractors = append([]ContextExtractor(nil), f.ContextExtractors...)
+	}
+
+	if f.StaticFields != nil {
+		clone.StaticFields = make(map[string]interface{}, len(f.StaticFields))
+		for k, v := range f.StaticFields {
+			clone.StaticFields[k] = v
+		}
+	}
+
+	if f.CorrelationKeys != nil {
+		clone.CorrelationKeys = append([]string(nil), f.CorrelationKeys...)
+	}
+
+	if f.VisibleFields != nil {
+		clone.VisibleFields = make(map[Level][]string, len(f.VisibleFields))
+		for level, keys := range f.VisibleFields {
+			clone.VisibleFields[level] = append([]string(nil), keys...)
+		}
+	}
+
+	if f.Redactors != nil {
+		clone.Redactors = append([]Redactor(nil), f.Redactors...)
+	}
+
+	if f.FieldOrder != nil {
+		clone.FieldOrder = append([]string(nil), f.FieldOrder...)
+	}
+
+	if f.FieldWeights != nil {
+		clone.FieldWeights = make(map[string]int, len(f.FieldWeights))
+		for k, v := range f.FieldWeights {
+			clone.FieldWeights[k] = v
+		}
+	}
+
+	if f.TruncationOrder != nil {
+		clone.TruncationOrder = append([]TruncationTarget(nil), f.TruncationOrder...)
+	}
+
+	if f.DiffKeys != nil {
+		clone.DiffKeys = append([]string(nil), f.DiffKeys...)
+	}
+
+	if f.LevelLabels != nil {
+		clone.LevelLabels = make(map[Level]string, len(f.LevelLabels))
+		for k, v := range f.LevelLabels {
+			clone.LevelLabels[k] = v
+		}
+	}
+
+	if f.PrefixColors != nil {
+		clone.PrefixColors = make(map[string]string, len(f.PrefixColors))
+		for k, v := range f.PrefixColors {
+			clone.PrefixColors[k] = v
+		}
+	}
+
+	if f.PrefixComponentStyles != nil {
+		clone.PrefixComponentStyles = append([]string(nil), f.PrefixComponentStyles...)
+	}
+
+	if f.FieldMap != nil {
+		clone.FieldMap = make(FieldMap, len(f.FieldMap))
+		for k, v := range f.FieldMap {
+			clone.FieldMap[k] = v
+		}
+	}
+
+	clone.fallback, clone.fallbackMatch = f.fallback, f.fallbackMatch
+
+	if t, ok := f.baseTimestamp.Load().(time.Time); ok {
+		clone.baseTimestamp.Store(t)
+	}
+
+	if colorScheme, ok := f.colorScheme.Load().(*compiledColorScheme); ok {
+		clone.colorScheme.Store(colorScheme)
+	}
+	return clone
+}
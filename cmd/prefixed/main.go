@@ -0,0 +1,55 @@
+// Command prefixed renders JSON logs read from stdin or the named files in the
+// colored prefixed layout:
+//
+//	kubectl logs pod | prefixed
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func main() {
+	f := &prefixed.TextFormatter{FieldMap: prefixed.FieldMap{}}
+	flag.BoolVar(&f.ForceColors, "force-colors", false, "output colors even when stdout is not a terminal")
+	flag.BoolVar(&f.DisableColors, "disable-colors", false, "never output colors")
+	flag.BoolVar(&f.DisableTimestamp, "disable-timestamp", false, "omit timestamps")
+	flag.StringVar(&f.TimestampFormat, "timestamp-format", "", "timestamp layout, e.g. 2006-01-02T15:04:05Z07:00")
+	flag.BoolVar(&f.DisableSorting, "disable-sorting", false, "keep fields in input order instead of sorting them")
+	flag.IntVar(&f.SpacePadding, "space-padding", 0, "pad messages with spaces to this width")
+	timeKey := flag.String("time-key", prefixed.FieldKeyTime, "input key holding the timestamp")
+	levelKey := flag.String("level-key", prefixed.FieldKeyLevel, "input key holding the level")
+	msgKey := flag.String("msg-key", prefixed.FieldKeyMsg, "input key holding the message")
+	prefixKey := flag.String("prefix-key", prefixed.FieldKeyPrefix, "input key holding the prefix")
+	flag.Parse()
+
+	f.FieldMap[prefixed.FieldKeyTime] = *timeKey
+	f.FieldMap[prefixed.FieldKeyLevel] = *levelKey
+	f.FieldMap[prefixed.FieldKeyMsg] = *msgKey
+	f.FieldMap[prefixed.FieldKeyPrefix] = *prefixKey
+
+	if flag.NArg() == 0 {
+		reformat(f, os.Stdin)
+		return
+	}
+	for _, name := range flag.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		reformat(f, file)
+		file.Close()
+	}
+}
+
+func reformat(f *prefixed.TextFormatter, r io.Reader) {
+	if err := f.Reformat(r, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
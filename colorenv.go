@@ -0,0 +1,19 @@
+package prefixed
+
+import "os"
+
+// envColors returns the color decision of the NO_COLOR, CLICOLOR_FORCE and
+// CLICOLOR environment variables, if any. See https://no-color.org and
+// https://bixense.com/clicolors.
+func envColors() (colored bool, ok bool) {
+	if os.Getenv("NO_COLOR") != "" {
+		return false, true
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true, true
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return false, true
+	}
+	return false, false
+}
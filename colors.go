@@ -0,0 +1,312 @@
+package prefixed
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// ColorScheme configures the styles used in colored output. Styles use the
+// github.com/mgutz/ansi syntax, e.g. "red", "yellow+b" or "white:blue".
+// Colors may also be 256-color palette indices such as "208", or 24-bit hex
+// colors such as "#ff8800", which are downgraded to the nearest 256 or 8
+// color when COLORTERM doesn't announce truecolor support. Empty styles fall
+// back to the default scheme.
+type ColorScheme struct {
+	// Name of the scheme, used in diagnostics.
+	Name string
+
+	InfoLevelStyle   string
+	WarnLevelStyle   string
+	ErrorLevelStyle  string
+	FatalLevelStyle  string
+	PanicLevelStyle  string
+	DebugLevelStyle  string
+	TraceLevelStyle  string
+	PrefixStyle      string
+	TimestampStyle   string
+	CallerStyle      string
+	ErrorDetailStyle string
+
+	// Styles of field keys and values. Keys default to the level color,
+	// values to no style.
+	FieldKeyStyle   string
+	FieldValueStyle string
+
+	// Styles of messages by level. Messages default to no style.
+	InfoMessageStyle  string
+	WarnMessageStyle  string
+	ErrorMessageStyle string
+	FatalMessageStyle string
+	PanicMessageStyle string
+	DebugMessageStyle string
+	TraceMessageStyle string
+}
+
+type compiledColorScheme struct {
+	InfoLevelColor   func(string) string
+	WarnLevelColor   func(string) string
+	ErrorLevelColor  func(string) string
+	FatalLevelColor  func(string) string
+	PanicLevelColor  func(string) string
+	DebugLevelColor  func(string) string
+	TraceLevelColor  func(string) string
+	PrefixColor      func(string) string
+	TimestampColor   func(string) string
+	CallerColor      func(string) string
+	ErrorDetailColor func(string) string
+
+	// Nil when keys use the level color.
+	FieldKeyColor   func(string) string
+	FieldValueColor func(string) string
+
+	InfoMessageColor  func(string) string
+	WarnMessageColor  func(string) string
+	ErrorMessageColor func(string) string
+	FatalMessageColor func(string) string
+	PanicMessageColor func(string) string
+	DebugMessageColor func(string) string
+	TraceMessageColor func(string) string
+
+	// Styles the scheme was compiled from, with defaults filled in
+	styles ColorScheme
+}
+
+var (
+	defaultColorScheme = &ColorScheme{
+		Name:             "default",
+		InfoLevelStyle:   "green",
+		WarnLevelStyle:   "yellow",
+		ErrorLevelStyle:  "red",
+		FatalLevelStyle:  "red",
+		PanicLevelStyle:  "red",
+		DebugLevelStyle:  "blue",
+		TraceLevelStyle:  "magenta",
+		PrefixStyle:      "cyan",
+		TimestampStyle:   "black+h",
+		CallerStyle:      "black+h",
+		ErrorDetailStyle: "red",
+	}
+	defaultCompiledColorScheme = compileColorScheme(defaultColorScheme)
+)
+
+func styleOrDefault(main string, fallback string) string {
+	if main != "" {
+		return main
+	}
+	return fallback
+}
+
+func compileColorScheme(s *ColorScheme) *compiledColorScheme {
+	styles := ColorScheme{
+		Name:             styleOrDefault(s.Name, "custom"),
+		InfoLevelStyle:   styleOrDefault(s.InfoLevelStyle, defaultColorScheme.InfoLevelStyle),
+		WarnLevelStyle:   styleOrDefault(s.WarnLevelStyle, defaultColorScheme.WarnLevelStyle),
+		ErrorLevelStyle:  styleOrDefault(s.ErrorLevelStyle, defaultColorScheme.ErrorLevelStyle),
+		FatalLevelStyle:  styleOrDefault(s.FatalLevelStyle, defaultColorScheme.FatalLevelStyle),
+		PanicLevelStyle:  styleOrDefault(s.PanicLevelStyle, defaultColorScheme.PanicLevelStyle),
+		DebugLevelStyle:  styleOrDefault(s.DebugLevelStyle, defaultColorScheme.DebugLevelStyle),
+		TraceLevelStyle:  styleOrDefault(s.TraceLevelStyle, defaultColorScheme.TraceLevelStyle),
+		PrefixStyle:      styleOrDefault(s.PrefixStyle, defaultColorScheme.PrefixStyle),
+		TimestampStyle:   styleOrDefault(s.TimestampStyle, defaultColorScheme.TimestampStyle),
+		CallerStyle:      styleOrDefault(s.CallerStyle, defaultColorScheme.CallerStyle),
+		ErrorDetailStyle: styleOrDefault(s.ErrorDetailStyle, defaultColorScheme.ErrorDetailStyle),
+
+		FieldKeyStyle:   s.FieldKeyStyle,
+		FieldValueStyle: s.FieldValueStyle,
+
+		InfoMessageStyle:  s.InfoMessageStyle,
+		WarnMessageStyle:  s.WarnMessageStyle,
+		ErrorMessageStyle: s.ErrorMessageStyle,
+		FatalMessageStyle: s.FatalMessageStyle,
+		PanicMessageStyle: s.PanicMessageStyle,
+		DebugMessageStyle: s.DebugMessageStyle,
+		TraceMessageStyle: s.TraceMessageStyle,
+	}
+	depth := terminalColorDepth()
+	var fieldKeyColor func(string) string
+	if styles.FieldKeyStyle != "" {
+		fieldKeyColor = styleColorFunc(styles.FieldKeyStyle, depth)
+	}
+	return &compiledColorScheme{
+		InfoLevelColor:   styleColorFunc(styles.InfoLevelStyle, depth),
+		WarnLevelColor:   styleColorFunc(styles.WarnLevelStyle, depth),
+		ErrorLevelColor:  styleColorFunc(styles.ErrorLevelStyle, depth),
+		FatalLevelColor:  styleColorFunc(styles.FatalLevelStyle, depth),
+		PanicLevelColor:  styleColorFunc(styles.PanicLevelStyle, depth),
+		DebugLevelColor:  styleColorFunc(styles.DebugLevelStyle, depth),
+		TraceLevelColor:  styleColorFunc(styles.TraceLevelStyle, depth),
+		PrefixColor:      styleColorFunc(styles.PrefixStyle, depth),
+		TimestampColor:   styleColorFunc(styles.TimestampStyle, depth),
+		CallerColor:      styleColorFunc(styles.CallerStyle, depth),
+		ErrorDetailColor: styleColorFunc(styles.ErrorDetailStyle, depth),
+
+		FieldKeyColor:   fieldKeyColor,
+		FieldValueColor: styleColorFunc(styles.FieldValueStyle, depth),
+
+		InfoMessageColor:  styleColorFunc(styles.InfoMessageStyle, depth),
+		WarnMessageColor:  styleColorFunc(styles.WarnMessageStyle, depth),
+		ErrorMessageColor: styleColorFunc(styles.ErrorMessageStyle, depth),
+		FatalMessageColor: styleColorFunc(styles.FatalMessageStyle, depth),
+		PanicMessageColor: styleColorFunc(styles.PanicMessageStyle, depth),
+		DebugMessageColor: styleColorFunc(styles.DebugMessageStyle, depth),
+		TraceMessageColor: styleColorFunc(styles.TraceMessageStyle, depth),
+
+		styles: styles,
+	}
+}
+
+func (s *compiledColorScheme) levelColor(level Level) func(string) string {
+	switch level {
+	case InfoLevel:
+		return s.InfoLevelColor
+	case WarnLevel:
+		return s.WarnLevelColor
+	case ErrorLevel:
+		return s.ErrorLevelColor
+	case FatalLevel:
+		return s.FatalLevelColor
+	case PanicLevel:
+		return s.PanicLevelColor
+	case TraceLevel:
+		return s.TraceLevelColor
+	default:
+		return s.DebugLevelColor
+	}
+}
+
+func (s *compiledColorScheme) messageColor(level Level) func(string) string {
+	switch level {
+	case InfoLevel:
+		return s.InfoMessageColor
+	case WarnLevel:
+		return s.WarnMessageColor
+	case ErrorLevel:
+		return s.ErrorMessageColor
+	case FatalLevel:
+		return s.FatalMessageColor
+	case PanicLevel:
+		return s.PanicMessageColor
+	case TraceLevel:
+		return s.TraceMessageColor
+	default:
+		return s.DebugMessageColor
+	}
+}
+
+// fieldKeyColor returns the color of field keys of entries of level.
+func (s *compiledColorScheme) fieldKeyColor(level Level) func(string) string {
+	if s.FieldKeyColor != nil {
+		return s.FieldKeyColor
+	}
+	return s.levelColor(level)
+}
+
+// Palette of distinguishable 256-color codes used for hash-derived colors.
+var hashPalette = []string{"33", "39", "45", "69", "75", "81", "105", "111", "117", "141", "147", "153", "170", "177", "183", "207", "213", "219", "214", "220", "226", "190", "118", "82", "48", "43"}
+
+// hashColor returns a color derived from s, stable across entries and runs,
+// downgraded like other styles on terminals without 256 colors.
+func (f *TextFormatter) hashColor(s string) func(string) string {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return f.styleColor(hashPalette[h.Sum32()%uint32(len(hashPalette))])
+}
+
+// Validate reports every style of the scheme that is not valid ansi syntax.
+func (s *ColorScheme) Validate() error {
+	var invalid []string
+	for _, style := range []struct{ name, value string }{
+		{"InfoLevelStyle", s.InfoLevelStyle},
+		{"WarnLevelStyle", s.WarnLevelStyle},
+		{"ErrorLevelStyle", s.ErrorLevelStyle},
+		{"FatalLevelStyle", s.FatalLevelStyle},
+		{"PanicLevelStyle", s.PanicLevelStyle},
+		{"DebugLevelStyle", s.DebugLevelStyle},
+		{"TraceLevelStyle", s.TraceLevelStyle},
+		{"PrefixStyle", s.PrefixStyle},
+		{"TimestampStyle", s.TimestampStyle},
+		{"CallerStyle", s.CallerStyle},
+		{"ErrorDetailStyle", s.ErrorDetailStyle},
+		{"FieldKeyStyle", s.FieldKeyStyle},
+		{"FieldValueStyle", s.FieldValueStyle},
+		{"InfoMessageStyle", s.InfoMessageStyle},
+		{"WarnMessageStyle", s.WarnMessageStyle},
+		{"ErrorMessageStyle", s.ErrorMessageStyle},
+		{"FatalMessageStyle", s.FatalMessageStyle},
+		{"PanicMessageStyle", s.PanicMessageStyle},
+		{"DebugMessageStyle", s.DebugMessageStyle},
+		{"TraceMessageStyle", s.TraceMessageStyle},
+	} {
+		if !validStyle(style.value) {
+			invalid = append(invalid, invalidStyle(style.name, style.value))
+		}
+	}
+
+	return invalidStylesError(invalid)
+}
+
+func invalidStylesError(invalid []string) error {
+	if len(invalid) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid color scheme styles: %s (styles have the form color[+attributes][:color[+attributes]], where color is one of %s, 0-255 or #rrggbb and attributes are any of %q)",
+		strings.Join(invalid, ", "), strings.Join(colorNames(), ", "), styleAttributes)
+}
+
+func invalidStyle(name, style string) string {
+	return fmt.Sprintf("%s %q", name, style)
+}
+
+// styleAttributes are the attribute letters accepted by ansi: bold, blink,
+// underline, inverse and high intensity.
+const styleAttributes = "bBuih"
+
+func validStyle(style string) bool {
+	if style == "" || style == "reset" || style == "off" {
+		return true
+	}
+	parts := strings.Split(style, ":")
+	if len(parts) > 2 {
+		return false
+	}
+	for i, part := range parts {
+		color := part
+		attributes := ""
+		if plus := strings.IndexByte(part, '+'); plus >= 0 {
+			color, attributes = part[:plus], part[plus+1:]
+		}
+		// The foreground color may be omitted when only attributes are set.
+		if !(color == "" && i == 0 && attributes != "") && !validColor(color) {
+			return false
+		}
+		if strings.Trim(attributes, styleAttributes) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func validColor(color string) bool {
+	if _, ok := ansi.Colors[color]; ok {
+		return true
+	}
+	if _, _, _, ok := parseHexColor(color); ok {
+		return true
+	}
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+func colorNames() []string {
+	names := make([]string, 0, len(ansi.Colors))
+	for name := range ansi.Colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
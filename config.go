@@ -0,0 +1,125 @@
+package prefixed
+
+import "fmt"
+
+// Config describes the effective configuration of a formatter, with defaults
+// applied, e.g. for logging it at startup or exposing it for support.
+type Config struct {
+	// Whether colored output is used for the destination of the latest
+	// entry. Before the first entry, only ForceColors enables colors.
+	Colors bool
+
+	// Timestamp rendering: "disabled", "relative", "relative-ms",
+	// "relative-clock", "relative-human" or "full", in which case
+	// TimestampFormat holds the layout.
+	Timestamp       string
+	TimestampFormat string
+	TimestampUTC    bool
+	TimestampEpoch  EpochUnit
+
+	CompactMode      bool
+	Sorting          bool
+	SpacePadding     int
+	Quoting          bool
+	StrictLogfmt     bool
+	NormalizeUnicode bool
+	SyslogPriority   bool
+	SyslogFacility   int
+	NumericLevel     NumericLevel
+	WarnLabel        WarnLabel
+
+	// Whether callers are reported, and up to which level.
+	ReportCaller bool
+	CallerLevel  Level
+
+	LineEnding   string
+	CrashSummary bool
+
+	// Key names for the reserved fields, indexed by their default name.
+	FieldMap map[string]string
+
+	// Name of the encoder used for the current color mode.
+	Encoder string
+
+	// Layout template of colored output, if any.
+	LayoutTemplate string
+
+	// Styles in effect for colored output.
+	ColorScheme ColorScheme
+}
+
+// Config returns the effective configuration of the formatter.
+func (f *TextFormatter) Config() Config {
+	isColored := f.isColored()
+	layout := f.timestampFormat()
+	if isColored {
+		layout = f.coloredTimestampFormat()
+	}
+	c := Config{
+		Colors:           isColored,
+		TimestampFormat:  withPrecision(layout, f.TimestampPrecision),
+		TimestampUTC:     f.TimestampUTC,
+		TimestampEpoch:   f.TimestampEpoch,
+		CompactMode:      f.CompactMode,
+		Sorting:          !f.DisableSorting,
+		SpacePadding:     f.SpacePadding,
+		Quoting:          !f.DisableQuoting || f.StrictLogfmt,
+		StrictLogfmt:     f.StrictLogfmt,
+		NormalizeUnicode: f.NormalizeUnicode,
+		SyslogPriority:   f.SyslogPriority,
+		SyslogFacility:   f.SyslogFacility,
+		NumericLevel:     f.NumericLevel,
+		WarnLabel:        f.WarnLabel,
+		CrashSummary:     f.CrashSummary,
+		FieldMap:         map[string]string{},
+		ColorScheme:      f.compiledColorScheme().styles,
+	}
+
+	switch {
+	case f.DisableTimestamp:
+		c.Timestamp = "disabled"
+	case f.ShortTimestamp && f.HumanShortTimestamp:
+		c.Timestamp = "relative-human"
+	case f.ShortTimestamp && f.ShortTimestampUnit == ShortTimestampMillis:
+		c.Timestamp = "relative-ms"
+	case f.ShortTimestamp && f.ShortTimestampUnit == ShortTimestampClock:
+		c.Timestamp = "relative-clock"
+	case f.ShortTimestamp:
+		c.Timestamp = "relative"
+	default:
+		c.Timestamp = "full"
+	}
+
+	if f.ReportCaller {
+		c.ReportCaller = true
+		c.CallerLevel = f.callerLevel()
+	}
+
+	if c.SyslogFacility == 0 {
+		c.SyslogFacility = 1
+	}
+
+	switch f.LineEnding {
+	case LineEndingCRLF:
+		c.LineEnding = "\r\n"
+	case LineEndingNone:
+		c.LineEnding = ""
+	default:
+		c.LineEnding = "\n"
+	}
+
+	for _, key := range []fieldKey{FieldKeyTime, FieldKeyLevel, FieldKeyMsg, FieldKeyPrefix} {
+		c.FieldMap[string(key)] = f.FieldMap.resolve(key)
+	}
+
+	switch e := f.encoder(isColored).(type) {
+	case *coloredEncoder:
+		c.Encoder = "colored"
+		c.LayoutTemplate = f.LayoutTemplate
+	case *logfmtEncoder:
+		c.Encoder = "logfmt"
+	default:
+		c.Encoder = fmt.Sprintf("%T", e)
+	}
+	return c
+}
@@ -0,0 +1,152 @@
+package prefixed
+
+import "fmt"
+
+// FileConfig holds the formatter options that can be read from config files,
+// e.g. by the prefixedconfig package. Keys are the field names, matched
+// case-insensitively, e.g.
+//
+//	ForceColors: true
+//	MinLevel: info
+//	LevelLabels: {warning: WRN, error: ERR}
+//	ColorScheme: {WarnLevelStyle: "yellow+b"}
+//
+// Levels are given by name. Options left out keep their default.
+//
+// FileConfig covers the options operators commonly tune: colors, timestamps,
+// sorting, padding, quoting, prefix filtering and styles, truncation, level
+// labels, caller reporting, wrapping, the layout template and the color
+// scheme.
+// Other TextFormatter options, notably those holding functions or Go values
+// such as Clock, Encoder, Redactors or ContextExtractors, and those with
+// enumerated values such as LineEnding or WarnLabel, can only be set in code,
+// e.g. on the formatter returned by Formatter.
+type FileConfig struct {
+	ForceColors           bool
+	DisableColors         bool
+	DisableTimestamp      bool
+	ShortTimestamp        bool
+	HumanShortTimestamp   bool
+	TimestampFormat       string
+	TimestampMillis       bool
+	TimestampUTC          bool
+	CompactMode           bool
+	CLIMode               bool
+	DisableSorting        bool
+	FieldOrder            []string
+	SpacePadding          int
+	SpacePaddingAuto      bool
+	DisableQuoting        bool
+	StrictLogfmt          bool
+	MinLevel              string
+	OnlyPrefixes          []string
+	ExcludePrefixes       []string
+	CorrelationKeys       []string
+	MaxLineLength         int
+	NormalizeUnicode      bool
+	LevelLabels           map[string]string
+	LevelPadding          int
+	ReportCaller          bool
+	CallerLevel           string
+	CallerFunction        bool
+	PrefixColors          map[string]string
+	PrefixComponentStyles []string
+	AutoPrefixColors      bool
+	PrefixPadding         int
+	MaxPrefixLength       int
+	MaxAutoPadding        int
+	PrefixDelimiter       string
+	PrefixDepth           int
+	WrapLines             bool
+	WrapWidth             int
+	LayoutTemplate        string
+
+	// Color scheme, with the keys of color scheme files.
+	ColorScheme *ColorScheme
+}
+
+// Formatter returns a formatter configured with c. Invalid levels, styles,
+// including prefix styles, and layout templates are reported as errors naming
+// the option.
+func (c *FileConfig) Formatter() (*TextFormatter, error) {
+	f := &TextFormatter{
+		ForceColors:           c.ForceColors,
+		DisableColors:         c.DisableColors,
+		DisableTimestamp:      c.DisableTimestamp,
+		ShortTimestamp:        c.ShortTimestamp,
+		HumanShortTimestamp:   c.HumanShortTimestamp,
+		TimestampFormat:       c.TimestampFormat,
+		TimestampMillis:       c.TimestampMillis,
+		TimestampUTC:          c.TimestampUTC,
+		CompactMode:           c.CompactMode,
+		CLIMode:               c.CLIMode,
+		DisableSorting:        c.DisableSorting,
+		FieldOrder:            c.FieldOrder,
+		SpacePadding:          c.SpacePadding,
+		SpacePaddingAuto:      c.SpacePaddingAuto,
+		DisableQuoting:        c.DisableQuoting,
+		StrictLogfmt:          c.StrictLogfmt,
+		OnlyPrefixes:          c.OnlyPrefixes,
+		ExcludePrefixes:       c.ExcludePrefixes,
+		CorrelationKeys:       c.CorrelationKeys,
+		MaxLineLength:         c.MaxLineLength,
+		NormalizeUnicode:      c.NormalizeUnicode,
+		LevelPadding:          c.LevelPadding,
+		ReportCaller:          c.ReportCaller,
+		CallerFunction:        c.CallerFunction,
+		PrefixColors:          c.PrefixColors,
+		PrefixComponentStyles: c.PrefixComponentStyles,
+		AutoPrefixColors:      c.AutoPrefixColors,
+		PrefixPadding:         c.PrefixPadding,
+		MaxPrefixLength:       c.MaxPrefixLength,
+		MaxAutoPadding:        c.MaxAutoPadding,
+		PrefixDelimiter:       c.PrefixDelimiter,
+		PrefixDepth:           c.PrefixDepth,
+		WrapLines:             c.WrapLines,
+		WrapWidth:             c.WrapWidth,
+		LayoutTemplate:        c.LayoutTemplate,
+	}
+
+	var err error
+	if f.MinLevel, err = configLevel("MinLevel", c.MinLevel); err != nil {
+		return nil, err
+	}
+	if f.CallerLevel, err = configLevel("CallerLevel", c.CallerLevel); err != nil {
+		return nil, err
+	}
+	if len(c.LevelLabels) > 0 {
+		f.LevelLabels = make(map[Level]string, len(c.LevelLabels))
+		for name, label := range c.LevelLabels {
+			level, ok := parseLevel(name)
+			if !ok {
+				return nil, fmt.Errorf("LevelLabels: unknown level %q", name)
+			}
+			f.LevelLabels[level] = label
+		}
+	}
+	if c.ColorScheme != nil {
+		if err := f.SetColorScheme(c.ColorScheme); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := f.layout(); err != nil {
+		return nil, fmt.Errorf("LayoutTemplate: %v", err)
+	}
+	return f, nil
+}
+
+// configLevel parses the level option name. An empty value leaves the option
+// nil, i.e. at its default.
+func configLevel(option, name string) (*Level, error) {
+	if name == "" {
+		return nil, nil
+	}
+	level, ok := parseLevel(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown level %q", option, name)
+	}
+	return &level, nil
+}
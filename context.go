@@ -0,0 +1,38 @@
+package prefixed
+
+import (
+	"context"
+	"time"
+)
+
+// ContextExtractor returns fields derived from the context of an entry, e.g.
+// trace or user IDs, so call sites don't need WithFields. Entry contexts are
+// set by logrus versions supporting WithContext.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// ContextValue returns an extractor rendering the context value stored under
+// key as field, if present.
+func ContextValue(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if v := ctx.Value(key); v != nil {
+			return map[string]interface{}{field: v}
+		}
+		return nil
+	}
+}
+
+// ContextDeadline returns an extractor rendering the time remaining until the
+// context deadline as field, if the context has one. The remaining time is
+// measured by the Clock of the formatter.
+func ContextDeadline(field string) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if deadline, ok := ctx.Deadline(); ok {
+			return map[string]interface{}{field: contextDeadline(deadline)}
+		}
+		return nil
+	}
+}
+
+// contextDeadline is a deadline extracted by ContextDeadline, replaced by the
+// time remaining until it when merged into the fields of an entry.
+type contextDeadline time.Time
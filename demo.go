@@ -0,0 +1,124 @@
+package prefixed
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// DemoOptions configures the stream of entries written by Demo.
+type DemoOptions struct {
+	// Seed of the random generator. Equal seeds produce equal streams.
+	Seed int64
+
+	// Number of entries to write. Defaults to 20.
+	Count int
+
+	// Formatter rendering the entries. Defaults to a TextFormatter with
+	// colors forced on. It is cloned, so its state is not affected.
+	Formatter *TextFormatter
+
+	// Time of the first entry. Defaults to 2016-10-27 00:44:26 UTC.
+	Start time.Time
+}
+
+// demoClock follows the times of the generated entries.
+type demoClock struct {
+	now time.Time
+}
+
+func (c *demoClock) Now() time.Time {
+	return c.now
+}
+
+var (
+	demoPrefixes = []string{"", "main", "api", "db", "worker", "server/http/router"}
+	demoLevels   = []Level{DebugLevel, DebugLevel, InfoLevel, InfoLevel, InfoLevel, InfoLevel, WarnLevel, ErrorLevel}
+	demoMessages = []string{
+		"Request served",
+		"Connection pool resized",
+		"Cache miss",
+		"Job scheduled",
+		"Retrying after failure",
+		"Configuration reloaded",
+		"Slow query detected",
+		"Shutting down gracefully",
+	}
+)
+
+// Demo writes a representative stream of entries across levels, prefixes
+// and field shapes, e.g. for theme previews and screenshots. The stream is
+// reproducible for a given seed.
+func Demo(w io.Writer, opts DemoOptions) error {
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	count := opts.Count
+	if count == 0 {
+		count = 20
+	}
+	start := opts.Start
+	if start.IsZero() {
+		start = time.Date(2016, 10, 27, 0, 44, 26, 0, time.UTC)
+	}
+
+	var f *TextFormatter
+	if opts.Formatter != nil {
+		f = opts.Formatter.Clone()
+	} else {
+		f = &TextFormatter{ForceColors: true}
+	}
+	clock := &demoClock{now: start}
+	f.Clock = clock
+	f.Since = nil
+	f.SetBaseTimestamp(start)
+
+	for i := 0; i < count; i++ {
+		clock.now = clock.now.Add(time.Duration(rng.Intn(1500)) * time.Millisecond)
+
+		data := demoFields(rng)
+		if prefix := demoPrefixes[rng.Intn(len(demoPrefixes))]; prefix != "" {
+			data["prefix"] = prefix
+		}
+		entry := &logEntry{
+			time:    clock.now,
+			level:   demoLevels[rng.Intn(len(demoLevels))],
+			message: demoMessages[rng.Intn(len(demoMessages))],
+			data:    data,
+		}
+
+		b, err := f.format(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// demoFields returns up to four fields of assorted shapes.
+func demoFields(rng *rand.Rand) map[string]interface{} {
+	shapes := []func() (string, interface{}){
+		func() (string, interface{}) { return "status", []int{200, 201, 404, 500}[rng.Intn(4)] },
+		func() (string, interface{}) { return "latency", time.Duration(rng.Intn(2000)) * time.Millisecond },
+		func() (string, interface{}) { return "user", fmt.Sprintf("user-%04d", rng.Intn(10000)) },
+		func() (string, interface{}) { return "ratio", float64(rng.Intn(1000)) / 1000 },
+		func() (string, interface{}) { return "cached", rng.Intn(2) == 0 },
+		func() (string, interface{}) { return "path", "/api/v1/items/" + fmt.Sprint(rng.Intn(100)) },
+		func() (string, interface{}) { return "note", "contains spaces and \"quotes\"" },
+		func() (string, interface{}) { return "error", errors.New("connection reset by peer") },
+		func() (string, interface{}) {
+			return "tags", map[string]interface{}{"region": "eu-west-1", "shard": rng.Intn(8)}
+		},
+	}
+
+	data := map[string]interface{}{}
+	for n := rng.Intn(5); n > 0; n-- {
+		key, value := shapes[rng.Intn(len(shapes))]()
+		data[key] = value
+	}
+	return data
+}
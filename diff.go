@@ -0,0 +1,46 @@
+package prefixed
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mgutz/ansi"
+)
+
+// Style of DiffKeys values that changed since the previous entry.
+var changedValueColor = ansi.ColorFunc("+b")
+
+// fieldHistory holds the last DiffKeys values seen per prefix.
+type fieldHistory struct {
+	mu   sync.Mutex
+	last map[string]map[string]string
+}
+
+// diffFields compares the DiffKeys fields of r with the previous entry with
+// the same prefix and remembers their values for the next one.
+func (f *TextFormatter) diffFields(r *Record) map[string]bool {
+	h := &f.fieldHistory
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last == nil {
+		h.last = make(map[string]map[string]string)
+	}
+	last, ok := h.last[r.Prefix]
+	if !ok {
+		last = make(map[string]string)
+		h.last[r.Prefix] = last
+	}
+
+	changed := make(map[string]bool)
+	for _, field := range r.Fields {
+		if !containsString(f.DiffKeys, field.Key) {
+			continue
+		}
+		value := fmt.Sprintf("%+v", field.Value)
+		previous, seen := last[field.Key]
+		changed[field.Key] = !seen || previous != value
+		last[field.Key] = value
+	}
+	return changed
+}
@@ -0,0 +1,63 @@
+package prefixed
+
+import (
+	"bytes"
+	"runtime"
+	"time"
+)
+
+// Encoder renders a processed entry into its final byte representation.
+// Implementations only deal with output syntax; prefix extraction, field
+// clash handling, normalization and ordering have already been applied to
+// the Record.
+type Encoder interface {
+	Encode(b *bytes.Buffer, r *Record) error
+}
+
+// Record is a log entry as prepared by the formatter for an Encoder.
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Prefix  string
+	Message string
+
+	// Location of the code that logged the entry, if reported.
+	Caller *runtime.Frame
+
+	// Fields in output order, excluding the prefix.
+	Fields []Field
+
+	// Whether the destination supports colors.
+	Colored bool
+
+	// Whether the entry requested the verbose multi-line layout.
+	Verbose bool
+
+	// Short hash identifying the entry, set when EntryIDs is enabled.
+	ID string
+
+	// Whether the values of DiffKeys fields changed since the previous entry
+	// with the same prefix, indexed by key. Nil unless DiffKeys is set.
+	Changed map[string]bool
+
+	// Destination of the entry, if known
+	terminal *terminal
+
+	// Prefix section extracted from the message, e.g. "[db] ", which plain
+	// output keeps in the message.
+	messagePrefix string
+
+	// Whether the record is only rendered to measure it for MaxLineLength,
+	// so encoders must not update state like learned widths.
+	measuring bool
+}
+
+// Field is a single key/value pair of a Record.
+type Field struct {
+	Key   string
+	Value interface{}
+
+	// Visible width the rendered value is shortened to by MaxLineLength, if
+	// positive. The value itself keeps its type.
+	width int
+}
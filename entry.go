@@ -0,0 +1,37 @@
+package prefixed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"time"
+)
+
+// logEntry is the formatter's view of a log entry. It is populated by the
+// adapter for the logrus import path the package is built against.
+type logEntry struct {
+	time    time.Time
+	level   Level
+	message string
+	data    map[string]interface{}
+
+	// Calling code as reported by logrus, if enabled and supported by the
+	// logrus version.
+	caller *runtime.Frame
+
+	// Context attached to the entry, if supported by the logrus version.
+	context context.Context
+
+	// Buffer provided by the logger to format into, if supported by the
+	// logrus version, or a pooled scratch buffer. It is owned by the logger
+	// or the pool: output is appended to it and it is never replaced.
+	buffer *bytes.Buffer
+
+	// Entry the logEntry was created from, for Fallback formatters. Nil for
+	// entries not originating from logrus.
+	source interface{}
+
+	// Destination of the entry, used for terminal detection. May be nil.
+	out io.Writer
+}
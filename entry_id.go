@@ -0,0 +1,17 @@
+package prefixed
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// entryID derives a short ID from the contents of r, stable across runs, so
+// metrics exemplars and error trackers can reference the exact log line.
+func entryID(r *Record) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d|%d|%s|%s", r.Time.UnixNano(), r.Level, r.Prefix, r.Message)
+	for _, field := range r.Fields {
+		fmt.Fprintf(h, "|%s=%+v", field.Key, field.Value)
+	}
+	return fmt.Sprintf("%06x", h.Sum32()&0xffffff)
+}
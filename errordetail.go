@@ -0,0 +1,65 @@
+package prefixed
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// unwrapError returns the error wrapped by err, supporting the standard
+// Unwrap method and the Cause method of github.com/pkg/errors.
+func unwrapError(err error) error {
+	switch err := err.(type) {
+	case interface{ Unwrap() error }:
+		return err.Unwrap()
+	case interface{ Cause() error }:
+		return err.Cause()
+	}
+	return nil
+}
+
+// stackTrace returns the frames of the innermost stack trace recorded in the
+// chain of err by errors implementing StackTrace(), as github.com/pkg/errors
+// does, one line per frame.
+func stackTrace(err error) []string {
+	var frames []string
+	for ; err != nil; err = unwrapError(err) {
+		method := reflect.ValueOf(err).MethodByName("StackTrace")
+		if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+			continue
+		}
+		trace := method.Call(nil)[0]
+		if trace.Kind() != reflect.Slice {
+			continue
+		}
+		frames = frames[:0]
+		for i := 0; i < trace.Len(); i++ {
+			// Frames print as "function\n\tfile:line" with %+v.
+			frame := strings.Replace(fmt.Sprintf("%+v", trace.Index(i).Interface()), "\n\t", " ", 1)
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+// hasErrorDetails reports whether err has a cause or a stack trace for
+// appendErrorDetails to write.
+func hasErrorDetails(err error) bool {
+	return unwrapError(err) != nil || len(stackTrace(err)) > 0
+}
+
+// appendErrorDetails writes the unwrap chain and the stack trace of err on
+// indented continuation lines.
+func (f *TextFormatter) appendErrorDetails(b *bytes.Buffer, err error, color func(string) string) {
+	for cause := unwrapError(err); cause != nil; cause = unwrapError(cause) {
+		b.WriteString(f.lineEnding())
+		b.WriteString(indent(0))
+		b.WriteString(color("caused by: " + f.normalize(singleLine(cause.Error()))))
+	}
+	for _, frame := range stackTrace(err) {
+		b.WriteString(f.lineEnding())
+		b.WriteString(indent(0))
+		b.WriteString(color("at " + frame))
+	}
+}
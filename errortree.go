@@ -0,0 +1,64 @@
+package prefixed
+
+import (
+	"bytes"
+	"strings"
+)
+
+// errorCauses returns the errors directly wrapped by err, supporting
+// errors.Join style, hashicorp/go-multierror style and single wrapping.
+func errorCauses(err error) []error {
+	switch err := err.(type) {
+	case interface{ Unwrap() []error }:
+		return err.Unwrap()
+	case interface{ WrappedErrors() []error }:
+		return err.WrappedErrors()
+	case interface{ Unwrap() error }:
+		if cause := err.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// hasMultipleCauses tells whether the cause tree of err branches anywhere.
+func hasMultipleCauses(err error) bool {
+	causes := errorCauses(err)
+	if len(causes) > 1 {
+		return true
+	}
+	for _, cause := range causes {
+		if hasMultipleCauses(cause) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendErrorTree writes the causes of err as an indented bullet list, one
+// continuation line per cause. Joined errors only group their causes and are
+// not listed themselves.
+func (f *TextFormatter) appendErrorTree(b *bytes.Buffer, err error, depth int, color func(string) string) {
+	for _, cause := range errorCauses(err) {
+		if cause == nil {
+			continue
+		}
+		if _, joined := cause.(interface{ Unwrap() []error }); joined {
+			f.appendErrorTree(b, cause, depth, color)
+			continue
+		}
+		b.WriteString(f.lineEnding())
+		b.WriteString(indent(depth))
+		b.WriteString(color("- " + f.normalize(singleLine(cause.Error()))))
+		f.appendErrorTree(b, cause, depth+1, color)
+	}
+}
+
+// singleLine joins the lines of multi-line error messages.
+func singleLine(s string) string {
+	return strings.Replace(s, "\n", "; ", -1)
+}
+
+func indent(depth int) string {
+	return string(bytes.Repeat([]byte("    "), depth+1))
+}
@@ -1,15 +1,12 @@
 package main
 
-import (
-	"github.com/Sirupsen/logrus"
-	prefixed "github.com/x-cray/logrus-prefixed-formatter"
-)
+import prefixed "github.com/x-cray/logrus-prefixed-formatter"
 
-var log = logrus.New()
+var log = newLogger()
 
 func init() {
 	log.Formatter = new(prefixed.TextFormatter)
-	log.Level = logrus.DebugLevel
+	log.Level = debugLevel
 }
 
 func main() {
@@ -17,7 +14,7 @@ func main() {
 		err := recover()
 		if err != nil {
 			// Fatal message
-			log.WithFields(logrus.Fields{
+			log.WithFields(fields{
 				"omg":    true,
 				"number": 100,
 			}).Fatal("[main] The ice breaks!")
@@ -25,32 +22,32 @@ func main() {
 	}()
 
 	// You could either provide a map key called `prefix` to add prefix
-	log.WithFields(logrus.Fields{
+	log.WithFields(fields{
 		"prefix": "main",
 		"animal": "walrus",
 		"number": 8,
 	}).Debug("Started observing beach")
 
 	// Or you can simply add prefix in square brackets within message itself
-	log.WithFields(logrus.Fields{
+	log.WithFields(fields{
 		"animal": "walrus",
 		"size":   10,
 	}).Debug("[main] A group of walrus emerges from the ocean")
 
 	// Warning message
-	log.WithFields(logrus.Fields{
+	log.WithFields(fields{
 		"omg":    true,
 		"number": 122,
 	}).Warn("[main] The group's number increased tremendously!")
 
 	// Information message
-	log.WithFields(logrus.Fields{
+	log.WithFields(fields{
 		"prefix":      "sensor",
 		"temperature": -4,
 	}).Info("Temperature changes")
 
 	// Panic message
-	log.WithFields(logrus.Fields{
+	log.WithFields(fields{
 		"prefix": "sensor",
 		"animal": "orca",
 		"size":   9009,
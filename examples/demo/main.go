@@ -0,0 +1,20 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+func main() {
+	seed := flag.Int64("seed", 1, "seed of the generated stream")
+	count := flag.Int("count", 20, "number of entries")
+	flag.Parse()
+
+	if err := prefixed.Demo(os.Stdout, prefixed.DemoOptions{Seed: *seed, Count: *count}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
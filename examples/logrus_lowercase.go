@@ -0,0 +1,15 @@
+//go:build !logrus_uppercase
+// +build !logrus_uppercase
+
+package main
+
+import "github.com/sirupsen/logrus"
+
+// The example refers to logrus through these names, so it builds with the
+// same import path as the formatter.
+
+type fields = logrus.Fields
+
+var newLogger = logrus.New
+
+const debugLevel = logrus.DebugLevel
@@ -0,0 +1,21 @@
+package prefixed
+
+type fieldKey string
+
+// FieldMap allows customization of the key names for the reserved fields.
+type FieldMap map[fieldKey]string
+
+// Default key names for the reserved fields.
+const (
+	FieldKeyTime   = "time"
+	FieldKeyLevel  = "level"
+	FieldKeyMsg    = "msg"
+	FieldKeyPrefix = "prefix"
+)
+
+func (f FieldMap) resolve(key fieldKey) string {
+	if k, ok := f[key]; ok {
+		return k
+	}
+	return string(key)
+}
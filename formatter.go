@@ -4,29 +4,139 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
-	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/Sirupsen/logrus"
-	"github.com/mgutz/ansi"
+	"golang.org/x/text/unicode/norm"
 )
 
-const reset = ansi.Reset
+func (f *TextFormatter) miniTS() int {
+	return int(f.elapsed() / time.Second)
+}
 
-var (
-	baseTimestamp time.Time
-)
+// miniTSText renders the short timestamp in ShortTimestampUnit.
+func (f *TextFormatter) miniTSText() string {
+	switch f.ShortTimestampUnit {
+	case ShortTimestampMillis:
+		return fmt.Sprintf("%07d", f.elapsed()/time.Millisecond)
+	case ShortTimestampClock:
+		s := f.miniTS()
+		if s < 60*60 {
+			return fmt.Sprintf("%02d:%02d", s/60, s%60)
+		}
+		return fmt.Sprintf("%d:%02d:%02d", s/(60*60), s%(60*60)/60, s%60)
+	default:
+		return fmt.Sprintf("%04d", f.miniTS())
+	}
+}
 
-func init() {
-	baseTimestamp = time.Now()
+func (f *TextFormatter) humanTS() string {
+	return humanDuration(f.elapsed())
 }
 
-func miniTS() int {
-	return int(time.Since(baseTimestamp) / time.Second)
+func humanDuration(d time.Duration) string {
+	s := int(d / time.Second)
+	switch {
+	case s < 60:
+		return fmt.Sprintf("%ds", s)
+	case s < 60*60:
+		return fmt.Sprintf("%dm%02ds", s/60, s%60)
+	case s < 24*60*60:
+		return fmt.Sprintf("%dh%02dm", s/(60*60), s%(60*60)/60)
+	default:
+		return fmt.Sprintf("%dd%02dh", s/(24*60*60), s%(24*60*60)/(60*60))
+	}
 }
 
+// PrefixSyntax selects how a prefix is extracted from the start of messages
+// without a prefix field.
+type PrefixSyntax int
+
+const (
+	// PrefixSyntaxBrackets extracts prefixes written as "[prefix] message".
+	// This is the default.
+	PrefixSyntaxBrackets PrefixSyntax = iota
+	// PrefixSyntaxColon extracts prefixes written as "prefix: message". The
+	// prefix can't contain spaces.
+	PrefixSyntaxColon
+	// PrefixSyntaxParens extracts prefixes written as "(prefix) message".
+	PrefixSyntaxParens
+	// PrefixSyntaxNone disables extraction, for applications logging
+	// messages that legitimately start with such text. Prefixes are then
+	// only set with the prefix field.
+	PrefixSyntaxNone
+)
+
+// ShortTimestampUnit selects the rendering of the short timestamp.
+type ShortTimestampUnit int
+
+const (
+	// ShortTimestampSeconds renders whole seconds, e.g. [0042]. This is the
+	// default.
+	ShortTimestampSeconds ShortTimestampUnit = iota
+	// ShortTimestampMillis renders milliseconds, e.g. [0042137].
+	ShortTimestampMillis
+	// ShortTimestampClock renders minutes and seconds, e.g. [00:42], with
+	// hours once an hour has passed, e.g. [1:02:03].
+	ShortTimestampClock
+)
+
+// LineEnding selects the sequence appended after each formatted entry.
+type LineEnding int
+
+const (
+	// LineEndingLF terminates entries with "\n". This is the default.
+	LineEndingLF LineEnding = iota
+	// LineEndingCRLF terminates entries with "\r\n".
+	LineEndingCRLF
+	// LineEndingNone appends nothing after entries.
+	LineEndingNone
+)
+
+// EmptyMessage selects how entries with an empty message are rendered.
+type EmptyMessage int
+
+const (
+	// EmptyMessageOmit leaves out the message, including its padding and the
+	// msg key. This is the default.
+	EmptyMessageOmit EmptyMessage = iota
+	// EmptyMessageQuoted renders the message as "" in colored mode and as
+	// msg="" in plain mode.
+	EmptyMessageQuoted
+	// EmptyMessagePlaceholder renders EmptyMessageText in place of the
+	// message.
+	EmptyMessagePlaceholder
+)
+
+// WarnLabel selects the spelling of the warning level, shared by colored and
+// plain output. JSON output always uses the logrus name, warning.
+type WarnLabel int
+
+const (
+	// WarnLabelLong spells the warning level as WARNING and level=warning,
+	// as logrus does. This is the default.
+	WarnLabelLong WarnLabel = iota
+	// WarnLabelShort spells the warning level as WARN and level=warn.
+	WarnLabelShort
+)
+
+// NumericLevel selects the numbering scheme of the level_num field.
+type NumericLevel int
+
+const (
+	// NumericLevelNone omits the level_num field. This is the default.
+	NumericLevelNone NumericLevel = iota
+	// NumericLevelLogrus emits logrus level numbers (panic 0 to trace 6).
+	NumericLevelLogrus
+	// NumericLevelSyslog emits syslog severities (emergency 0 to debug 7).
+	NumericLevelSyslog
+)
+
 type TextFormatter struct {
 	// Set to true to bypass checking for a TTY before outputting colors.
 	ForceColors bool
@@ -41,182 +151,936 @@ type TextFormatter struct {
 	// Enable logging of just the time passed since beginning of execution.
 	ShortTimestamp bool
 
+	// Render the short timestamp in human units (e.g. 1m23s, 2h05m) instead
+	// of a plain number of seconds.
+	HumanShortTimestamp bool
+
+	// Unit of the short timestamp. Defaults to ShortTimestampSeconds.
+	// Ignored with HumanShortTimestamp.
+	ShortTimestampUnit ShortTimestampUnit
+
 	// Timestamp format to use for display when a full timestamp is printed.
 	TimestampFormat string
 
+	// Append milliseconds to the default colored timestamp, e.g.
+	// [Jan  2 15:04:05.123]. Ignored when TimestampFormat is set.
+	TimestampMillis bool
+
+	// Render full timestamps in UTC instead of the local time zone.
+	TimestampUTC bool
+
+	// Fractional seconds of full timestamps, replacing those of the layout,
+	// e.g. TimestampPrecisionMillis renders time.RFC3339 as
+	// 2006-01-02T15:04:05.000Z07:00. Defaults to the layout's own.
+	TimestampPrecision TimestampPrecision
+
+	// Render the timestamp of plain and JSON output as a Unix time number
+	// in the given unit, e.g. time=1700000000123 with EpochMillis, for
+	// ingestion pipelines expecting numeric time. Colored output keeps
+	// its layout.
+	TimestampEpoch EpochUnit
+
+	// Field holding the time of the entry, used instead of the entry's own
+	// time, e.g. to render the original times of replayed events. The field
+	// may hold a time.Time, an RFC 3339 string or Unix seconds, and is not
+	// rendered as a field. Entries without it keep their own time.
+	TimeField string
+
+	// Layout for field values of type time.Time. Defaults to TimestampFormat.
+	TimeFieldFormat string
+
+	// Location field values of type time.Time are converted to before
+	// rendering. Nil keeps their own location.
+	TimeFieldLocation *time.Location
+
+	// Render numeric values of keys ending in _ns, _us, _ms, _sec, _seconds,
+	// _bytes, _pct or _percent with a human-readable unit, e.g.
+	// latency_ms=1532 as latency_ms=1.532s.
+	UnitConventions bool
+
+	// Drop the timestamp and level columns of Info and Debug entries in
+	// colored mode, keeping the full headline for Warn and above.
+	CompactMode bool
+
 	// The fields are sorted by default for a consistent output. For applications
 	// that log extremely frequently and don't use the JSON formatter this may not
 	// be desired.
+	//
+	// Deprecated: set SortingFunc to a function leaving the keys as they are.
 	DisableSorting bool
 
+	// Custom ordering of field keys, e.g. to print request_id first and
+	// error last. The keys are sorted in place. Defaults to alphabetical
+	// order, or to FieldWeights when set.
+	SortingFunc func(keys []string)
+
+	// Redactors replacing sensitive field values with [REDACTED] in every
+	// output mode, e.g. NewPatternRedactor([]string{"password", "*_token"}).
+	Redactors []Redactor
+
+	// Keys of fields printed first, in this order. The remaining fields follow
+	// in their usual order.
+	FieldOrder []string
+
+	// Sort weights of field keys. Fields with a higher weight are printed
+	// first, fields with equal weight alphabetically. Keys without a weight
+	// have weight zero, so negative weights move fields to the end.
+	FieldWeights map[string]int
+
 	// Pad msg field with spaces on the right for display.
 	// The value for this parameter will be the size of padding.
 	// Its default value is zero, which means no padding will be applied for msg.
 	SpacePadding int
 
-	// Whether the logger's out is to a terminal
-	isTerminal   bool
-	terminalOnce sync.Once
+	// Pad messages of colored entries to the widest message seen for their
+	// prefix, up to MaxAutoPadding columns, and truncate messages that
+	// would leave no room for fields on a line of the terminal. The width is
+	// detected per destination and, on Unix, again when the terminal is
+	// resized. Overrides SpacePadding.
+	SpacePaddingAuto bool
+
+	// Emit values as-is in plain mode, without quoting values that contain
+	// special characters.
+	DisableQuoting bool
+
+	// Emit plain output that standard logfmt parsers read back: values are
+	// quoted only where logfmt requires it, i.e. when they contain spaces,
+	// '=', quotes or control characters, invalid key characters are
+	// replaced with '_', and verbose fields stay on the entry's line.
+	// DisableQuoting is ignored.
+	StrictLogfmt bool
+
+	// Render entries less severe than *MinLevel as empty output, so a shared
+	// logger can feed outputs of different verbosity through a formatter per
+	// output. Nil disables the check, and PanicLevel renders panics only.
+	MinLevel *Level
+
+	// Only render entries with one of these prefixes. Other entries, including
+	// those without prefix, produce empty output.
+	OnlyPrefixes []string
+
+	// Render entries with one of these prefixes as empty output, e.g. to mute
+	// a noisy subsystem.
+	ExcludePrefixes []string
+
+	// Extractors deriving fields from the context attached to entries, e.g.
+	// trace IDs. Collisions are resolved by FieldMergePolicy.
+	ContextExtractors []ContextExtractor
+
+	// Fields added to every entry, e.g. service or version. Collisions are
+	// resolved by FieldMergePolicy.
+	StaticFields map[string]interface{}
+
+	// Precedence of entry, context and static fields sharing a key.
+	// Defaults to MergeEntryWins.
+	FieldMergePolicy FieldMergePolicy
+
+	// Restrict the fields rendered for entries of a level to the listed keys,
+	// e.g. a terse whitelist for InfoLevel while DebugLevel shows everything.
+	// Levels without an entry render all fields.
+	VisibleFields map[Level][]string
+
+	// Color the values of these keys (e.g. request_id, trace_id) with a
+	// color derived from the value, so all lines of one request share a hue.
+	CorrelationKeys []string
+
+	// Render the causes of error values whose cause chain branches (e.g.
+	// errors.Join or multierror values) as an indented list under the entry
+	// in colored mode.
+	ErrorTree bool
+
+	// Maximum number of visible characters of the first line of an entry.
+	// Longer entries are shortened in TruncationOrder. Zero means no limit.
+	MaxLineLength int
+
+	// Order in which parts of entries exceeding MaxLineLength are shortened,
+	// so the most relevant content survives. Defaults to dropping fields,
+	// then shortening field values, then shortening the message.
+	TruncationOrder []TruncationTarget
+
+	// Normalize message and string values to Unicode NFC and strip byte order
+	// marks, so output from differently normalized sources compares equal.
+	NormalizeUnicode bool
+
+	// Keep ANSI escape sequences found in messages, prefixes, field keys and
+	// string or error values. By default they are stripped, so escape codes
+	// in user-supplied data can't corrupt or spoof terminal output. Set it
+	// when styling is embedded intentionally.
+	AllowANSI bool
+
+	// Prepend a syslog priority tag (e.g. <14>) derived from the entry level,
+	// so output piped to logger(1) or a syslog socket keeps its severity.
+	SyslogPriority bool
+
+	// Syslog facility code used for the priority tag. Zero means user (1).
+	SyslogFacility int
+
+	// Labels of levels in colored output, e.g. {InfoLevel: "INF", WarnLevel:
+	// "WRN", ErrorLevel: "ERR"}. Levels without a label use their uppercase
+	// name.
+	LevelLabels map[Level]string
+
+	// Width level labels are right-aligned to in colored output. Defaults
+	// to the width of the widest label, e.g. 7 for WARNING.
+	LevelPadding int
+
+	// Rendering of empty messages, the same in every output mode. Defaults to
+	// EmptyMessageOmit.
+	EmptyMessage EmptyMessage
+
+	// Placeholder used with EmptyMessagePlaceholder. Defaults to "<empty>".
+	EmptyMessageText string
+
+	// Spelling of the warning level, so grep and alerting patterns match
+	// both colored and plain output.
+	WarnLabel WarnLabel
+
+	// Emit a numeric level_num field next to the level name in plain mode,
+	// for range-based queries such as level_num <= 3.
+	NumericLevel NumericLevel
+
+	// Report the file and line of the code that logged Warn and more severe
+	// entries. The caller is only looked up for levels up to CallerLevel, so
+	// less severe entries don't pay for it.
+	ReportCaller bool
+
+	// Least severe level the caller is reported for. Nil means WarnLevel, and
+	// PanicLevel reports callers of panics only.
+	CallerLevel *Level
+
+	// Render the function name of reported callers, trimmed according to
+	// CallerFunctionTrim and keeping the last CallerFunctionSegments segments
+	// of the package path unless zero.
+	CallerFunction         bool
+	CallerFunctionTrim     FunctionTrim
+	CallerFunctionSegments int
+
+	// Rendering of caller files, reported either by ReportCaller or by
+	// logrus with SetReportCaller(true). Defaults to CallerPathShort.
+	CallerPath CallerPath
+
+	// Called with the rendered bytes after formatting entries of AlertLevel
+	// and more severe, e.g. to ring the terminal bell or trigger a desktop
+	// notification. The bytes must not be modified or retained.
+	OnAlert func(level Level, line []byte)
+
+	// Called with the record and the rendered bytes of every entry after
+	// successful formatting, e.g. for in-memory search indexes, TUIs or test
+	// assertions. The bytes must not be modified or retained.
+	OnFormat func(r *Record, line []byte)
+
+	// Least severe level OnAlert is called for. Nil means ErrorLevel, and
+	// PanicLevel alerts on panics only.
+	AlertLevel *Level
+
+	// Stamp each entry with a short stable hash ID, rendered as a dim #a1b2c3
+	// suffix in colored mode and as id= in plain mode, so metrics exemplars
+	// or error trackers can reference the exact log line. OnEntryID, if set,
+	// is called with the ID and the record of each entry.
+	EntryIDs  bool
+	OnEntryID func(id string, r *Record)
+
+	// Let panics while formatting entries propagate. By default they are
+	// recovered from and the entry is rendered as "time level msg".
+	DisablePanicRecovery bool
+
+	// Called with errors the formatter recovered from, e.g. panics while
+	// formatting entries.
+	OnError func(error)
+
+	// Line terminator appended after each entry. Defaults to LineEndingLF.
+	LineEnding LineEnding
+
+	// Follow Fatal and Panic entries with a summary line of the warnings and
+	// errors seen so far and the time elapsed since start of execution.
+	CrashSummary bool
+
+	// Print the unwrap chain and the stack trace, as recorded by
+	// github.com/pkg/errors, of error fields on indented continuation lines
+	// below colored entries, styled with the scheme's ErrorDetailStyle.
+	ErrorDetails bool
+
+	// Limit heavy extras such as crash summaries and stack dumps to DumpRate
+	// per second, with bursts of up to DumpBurst, so a panic storm can't
+	// flood the output. Entries over the limit get a "(dump suppressed)"
+	// note instead. Zero means no limit.
+	DumpRate  float64
+	DumpBurst int
+
+	// FieldMap allows users to customize the names of keys for the reserved
+	// fields in plain output, e.g. {FieldKeyTime: "@timestamp"}, to match
+	// downstream ingestion schemas. It is also used to recognize them when
+	// reformatting JSON logs.
+	FieldMap FieldMap
+
+	// Clock providing the current time, e.g. for relative timestamps and
+	// entries created by the adapters. Defaults to the system clock.
+	Clock Clock
+
+	// Since computes the time elapsed since base for relative timestamps,
+	// overriding the Clock based computation.
+	Since func(base time.Time) time.Duration
+
+	// Keys whose values are highlighted in colored mode when they changed
+	// since the previous entry with the same prefix, and dimmed otherwise,
+	// e.g. to follow state transitions.
+	DiffKeys []string
+
+	// Styles of specific prefixes, e.g. {"db": "magenta"}, in the syntax of
+	// ColorScheme. Other prefixes use the scheme's PrefixStyle, unless
+	// AutoPrefixColors derives a stable color from their hash. Invalid styles,
+	// reported by Validate, are ignored.
+	PrefixColors     map[string]string
+	AutoPrefixColors bool
+
+	// Add a gid field holding the ID of the logging goroutine, so logs of
+	// concurrent requests can be correlated without manual instrumentation.
+	// GoroutineIDFunc, if set, provides the ID instead of parsing it from the
+	// goroutine's stack trace.
+	ShowGoroutineID bool
+	GoroutineIDFunc func() uint64
+
+	// Add host and pid fields, plus an exe field with ProcessExe, to every
+	// entry, e.g. when aggregating logs from many nodes. They are computed
+	// once, when the first entry is formatted.
+	ProcessMetadata bool
+	ProcessExe      bool
+
+	// Syntax of prefixes extracted from messages without a prefix field.
+	// Defaults to PrefixSyntaxBrackets.
+	PrefixSyntax PrefixSyntax
+
+	// Derive the prefix of entries without one from the package of the code
+	// that logged them, e.g. [storage] for mypkg/storage.
+	PrefixFromCaller bool
+
+	// Render only the first InlineFieldLimit fields of colored entries on
+	// the entry's line, and the remaining ones as an indented block of
+	// aligned columns below it. Zero renders all fields inline.
+	InlineFieldLimit int
+
+	// Pad the prefix section of colored output to this many columns, so that
+	// messages start at a consistent column. PrefixPaddingAuto pads to the
+	// widest prefix seen so far.
+	PrefixPadding int
+
+	// Truncate prefixes longer than MaxPrefixLength characters in colored
+	// output by cutting out their middle, e.g. net…handler. Zero means no
+	// limit.
+	MaxPrefixLength int
+
+	// Widest padding learned by automatic alignment such as
+	// PrefixPaddingAuto. Wider sections are rendered unpadded and don't
+	// widen the padding of other entries. Defaults to 40.
+	MaxAutoPadding int
+
+	// Separator of the components of hierarchical prefixes such as
+	// [server/http/router]. Defaults to "/".
+	PrefixDelimiter string
+
+	// Styles of the components of hierarchical prefixes in colored mode,
+	// indexed by depth. Components without a valid style use the prefix
+	// color; Validate reports invalid ones.
+	PrefixComponentStyles []string
+
+	// Show only the last PrefixDepth components of hierarchical prefixes in
+	// colored mode, e.g. …/http/router. Zero shows all components.
+	PrefixDepth int
+
+	// Emit a dim marker line with the time and number of lines in colored
+	// mode every WatermarkEvery entries and every WatermarkInterval, helping
+	// to keep temporal orientation while tailing chatty services.
+	WatermarkEvery    int
+	WatermarkInterval time.Duration
+
+	// Render Info entries as undecorated program output, without timestamp,
+	// level or prefix, while Warn and above keep the full diagnostic
+	// decoration. Useful for command line tools built on logrus.
+	CLIMode bool
+
+	// Soft-wrap long colored entries at WrapWidth columns, breaking at spaces
+	// and indenting continuation lines. WrapWidth defaults to the width of
+	// the terminal, the COLUMNS environment variable or 80 columns.
+	WrapLines bool
+	WrapWidth int
+
+	// Go text/template laying out colored entries, e.g.
+	// "{{.Level}} {{.Prefix}} {{.Message}}{{.Fields}}". Available sections
+	// are Timestamp, Level, Prefix, Message, Caller, Fields and ID, where Fields
+	// starts with a space unless empty. The template is compiled once.
+	LayoutTemplate string
+
+	// Encoder used to render entries. Defaults to the colored layout when
+	// colors are enabled and to logfmt otherwise.
+	Encoder Encoder
+
+	// Color scheme set with SetColorScheme, swapped atomically on reload
+	colorScheme atomic.Value
+
+	// Last values of DiffKeys fields
+	fieldHistory fieldHistory
+
+	// Compiled PrefixColors and PrefixComponentStyles styles
+	prefixColorCache sync.Map
+
+	// Base of relative timestamps set with SetBaseTimestamp
+	baseTimestamp atomic.Value
+
+	// Set to 1 by SuppressColors
+	colorsSuppressed int32
+
+	// LayoutTemplate compiled on first use
+	layoutTemplate *template.Template
+	layoutErr      error
+	layoutOnce     sync.Once
+
+	// Formatter set with Fallback and the prefixes it renders
+	fallback      func(entry *logEntry) ([]byte, error)
+	fallbackMatch func(prefix string) bool
+
+	// Token bucket for DumpRate
+	dumpLimiter dumpLimiter
+
+	// Fields injected with ProcessMetadata
+	metadata     []Field
+	metadataOnce sync.Once
+
+	// Capabilities of the destinations seen so far, by writer, and whether
+	// the latest entry went to a terminal
+	terminals    sync.Map
+	lastTerminal uint32
+
+	// Number of entries formatted so far, indexed by level
+	levelCounts [TraceLevel + 1]uint64
+
+	// Widest prefix seen for PrefixPaddingAuto
+	prefixWidths widthTracker
+
+	// Widest messages seen per prefix for SpacePaddingAuto
+	messageWidths widthTracker
+
+	// Entries seen and time of the last marker for watermarks
+	watermarkEntries uint64
+	watermarkTime    int64
 }
 
-func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
-	var keys []string = make([]string, 0, len(entry.Data))
-	for k := range entry.Data {
-		if k != "prefix" {
-			keys = append(keys, k)
-		}
+func (f *TextFormatter) formatEntry(entry *logEntry) ([]byte, error) {
+	if f.MinLevel != nil && entry.level > *f.MinLevel {
+		return []byte{}, nil
 	}
 
-	if !f.DisableSorting {
-		sort.Strings(keys)
+	if int(entry.level) < len(f.levelCounts) {
+		atomic.AddUint64(&f.levelCounts[entry.level], 1)
 	}
 
-	b := &bytes.Buffer{}
-
-	prefixFieldClashes(entry.Data)
+	terminal := f.terminal(entry.out)
+	if terminal.isTerminal {
+		atomic.StoreUint32(&f.lastTerminal, 1)
+	} else {
+		atomic.StoreUint32(&f.lastTerminal, 0)
+	}
 
-	f.terminalOnce.Do(func() {
-		if entry.Logger != nil {
-			f.isTerminal = logrus.IsTerminal(entry.Logger.Out)
-		}
-	})
+	isColored := f.isColoredFor(terminal.isTerminal)
 
-	isColored := (f.ForceColors || f.isTerminal) && !f.DisableColors
+	entry = f.mergeFields(entry)
+	if f.fallback != nil && entry.source != nil && f.fallbackMatch(f.entryPrefix(entry)) {
+		return f.fallback(entry)
+	}
+	record := f.newRecord(entry, isColored)
+	record.terminal = terminal
+	if f.isMuted(record.Prefix) {
+		return []byte{}, nil
+	}
 
-	timestampFormat := f.TimestampFormat
-	if timestampFormat == "" {
-		timestampFormat = time.Stamp
+	// Output goes straight into the logger's buffer when one is provided, so
+	// the returned bytes alias it and nothing else retains them.
+	b := entry.buffer
+	if b == nil {
+		b = &bytes.Buffer{}
 	}
+
 	if isColored {
-		f.printColored(b, entry, keys, timestampFormat)
-	} else {
-		if !f.DisableTimestamp {
-			f.appendKeyValue(b, "time", entry.Time.Format(timestampFormat))
+		f.appendWatermark(b)
+	}
+
+	if f.SyslogPriority {
+		fmt.Fprintf(b, "<%d>", f.syslogPriority(entry.level))
+	}
+
+	if len(f.DiffKeys) > 0 {
+		record.Changed = f.diffFields(record)
+	}
+
+	if entry.caller != nil {
+		record.Caller = entry.caller
+	} else if f.reportsCaller(entry.level) {
+		record.Caller = findCaller()
+	}
+	if f.EntryIDs {
+		record.ID = entryID(record)
+		if f.OnEntryID != nil {
+			f.OnEntryID(record.ID, record)
 		}
-		f.appendKeyValue(b, "level", entry.Level.String())
-		if entry.Message != "" {
-			f.appendKeyValue(b, "msg", entry.Message)
+	}
+
+	encoder := f.encoder(isColored)
+	if isJSONEntry(entry.data) {
+		encoder = &jsonEncoder{f}
+	} else if f.MaxLineLength > 0 && !record.Verbose {
+		if err := f.fitRecord(encoder, record); err != nil {
+			return nil, err
 		}
-		for _, key := range keys {
-			f.appendKeyValue(b, key, entry.Data[key])
+	}
+	start := b.Len()
+	if err := encoder.Encode(b, record); err != nil {
+		return nil, err
+	}
+	if f.WrapLines && isColored {
+		wrapped := f.wrapLines(b.String()[start:], f.wrapWidth(terminal))
+		b.Truncate(start)
+		b.WriteString(wrapped)
+	}
+
+	f.appendLineEnding(b)
+
+	if f.CrashSummary && entry.level <= FatalLevel {
+		if f.allowDump() {
+			f.printSummary(b, isColored)
+			f.appendLineEnding(b)
+		} else {
+			f.appendDumpSuppressed(b, isColored)
 		}
 	}
 
-	b.WriteByte('\n')
+	if f.alerts(entry.level) {
+		f.OnAlert(entry.level, b.Bytes())
+	}
+	if f.OnFormat != nil {
+		f.OnFormat(record, b.Bytes())
+	}
 	return b.Bytes(), nil
 }
 
-func (f *TextFormatter) printColored(b *bytes.Buffer, entry *logrus.Entry, keys []string, timestampFormat string) {
-	var levelColor string
-	var levelText string
-	switch entry.Level {
-	case logrus.InfoLevel:
-		levelColor = ansi.Green
-	case logrus.WarnLevel:
-		levelColor = ansi.Yellow
-	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
-		levelColor = ansi.Red
-	default:
-		levelColor = ansi.Blue
+// entryPrefix returns the prefix newRecord determines for entry, without
+// processing the rest of it.
+func (f *TextFormatter) entryPrefix(entry *logEntry) string {
+	if prefixValue, ok := entry.data["prefix"]; ok {
+		return f.normalize(fmt.Sprint(prefixValue))
+	}
+	prefix, _ := f.extractPrefix(f.normalize(entry.message))
+	if prefix == "" && f.PrefixFromCaller {
+		prefix = callerPrefix(entry)
+	}
+	return prefix
+}
+
+// newRecord performs the processing shared by all encoders: prefix
+// extraction, value normalization and key ordering.
+func (f *TextFormatter) newRecord(entry *logEntry, isColored bool) *Record {
+	record := &Record{
+		Time:    entry.time,
+		Level:   entry.level,
+		Message: f.normalize(entry.message),
+		Colored: isColored,
+		Fields:  make([]Field, 0, len(entry.data)),
 	}
 
-	if entry.Level != logrus.WarnLevel {
-		levelText = strings.ToUpper(entry.Level.String())
+	if prefixValue, ok := entry.data["prefix"]; ok {
+		record.Prefix = f.normalize(fmt.Sprint(prefixValue))
 	} else {
-		levelText = "WARN"
+		message := record.Message
+		record.Prefix, record.Message = f.extractPrefix(message)
+		if record.Prefix != "" {
+			trimmed := strings.TrimRightFunc(message, unicode.IsSpace)
+			record.messagePrefix = trimmed[:len(trimmed)-len(record.Message)]
+		}
+	}
+	if record.Message == "" && f.EmptyMessage == EmptyMessagePlaceholder {
+		record.Message = f.EmptyMessageText
+		if record.Message == "" {
+			record.Message = "<empty>"
+		}
+	}
+	if record.Prefix == "" && f.PrefixFromCaller {
+		record.Prefix = callerPrefix(entry)
 	}
 
-	prefix := ""
-	message := entry.Message
+	timeField := ""
+	if f.TimeField != "" {
+		if t, ok := fieldTime(entry.data[f.TimeField]); ok {
+			record.Time, timeField = t, f.TimeField
+		}
+	}
 
-	if prefixValue, ok := entry.Data["prefix"]; ok {
-		prefix = fmt.Sprint(" ", ansi.Cyan, prefixValue, ":", reset)
-	} else {
-		prefixValue, trimmedMsg := extractPrefix(entry.Message)
-		if len(prefixValue) > 0 {
-			prefix = fmt.Sprint(" ", ansi.Cyan, prefixValue, ":", reset)
-			message = trimmedMsg
+	visible, restricted := f.VisibleFields[entry.level]
+	record.Verbose = isVerbose(entry.data)
+	for k, v := range entry.data {
+		if k == VerboseKey || k == JSONKey || k == timeField || restricted && !containsString(visible, k) {
+			continue
+		}
+		if k != "prefix" {
+			record.Fields = append(record.Fields, Field{Key: f.sanitizeKey(k), Value: f.processValue(k, v)})
+		}
+	}
+	if _, ok := entry.data["gid"]; f.ShowGoroutineID && !ok {
+		record.Fields = append(record.Fields, Field{Key: "gid", Value: f.goroutineID()})
+	}
+	if f.ProcessMetadata {
+		for _, field := range f.processMetadata() {
+			if _, ok := entry.data[field.Key]; !ok {
+				record.Fields = append(record.Fields, field)
+			}
 		}
 	}
 
-	messageFormat := "%s"
-	if f.SpacePadding != 0 {
-		messageFormat = fmt.Sprintf("%%-%ds", f.SpacePadding)
+	f.sortFields(record.Fields)
+	return record
+}
+
+func (f *TextFormatter) encoder(isColored bool) Encoder {
+	if f.Encoder != nil {
+		return f.Encoder
+	}
+	if isColored {
+		return &coloredEncoder{f}
+	}
+	return &logfmtEncoder{f}
+}
+
+// isMuted tells whether entries with prefix are filtered out by OnlyPrefixes
+// or ExcludePrefixes.
+func (f *TextFormatter) isMuted(prefix string) bool {
+	if len(f.OnlyPrefixes) > 0 && !containsString(f.OnlyPrefixes, prefix) {
+		return true
+	}
+	return containsString(f.ExcludePrefixes, prefix)
+}
+
+// isProgramOutput reports whether entries of level are rendered as plain
+// program output in CLIMode.
+func (f *TextFormatter) isProgramOutput(level Level) bool {
+	return f.CLIMode && level == InfoLevel
+}
+
+// isColored decides whether colors are used for the destination of the
+// latest entry.
+func (f *TextFormatter) isColored() bool {
+	return f.isColoredFor(atomic.LoadUint32(&f.lastTerminal) == 1)
+}
+
+// isColoredFor decides whether colors are used for a destination. In order
+// of precedence, DisableColors and SuppressColors turn colors off,
+// ForceColors turns them on, then NO_COLOR, CLICOLOR_FORCE and CLICOLOR
+// apply, and otherwise colors are used on terminals.
+func (f *TextFormatter) isColoredFor(isTerminal bool) bool {
+	if f.DisableColors || f.colorsAreSuppressed() {
+		return false
+	}
+	if f.ForceColors {
+		return true
+	}
+	if colored, ok := envColors(); ok {
+		return colored
+	}
+	return isTerminal
+}
+
+// SetColorScheme replaces the default colors. Empty styles of colorScheme
+// keep their default. An error listing the invalid styles is returned, and
+// the current scheme is kept, when colorScheme fails validation.
+func (f *TextFormatter) SetColorScheme(colorScheme *ColorScheme) error {
+	if err := colorScheme.Validate(); err != nil {
+		return err
+	}
+	f.colorScheme.Store(compileColorScheme(colorScheme))
+	return nil
+}
+
+func (f *TextFormatter) compiledColorScheme() *compiledColorScheme {
+	if colorScheme, ok := f.colorScheme.Load().(*compiledColorScheme); ok {
+		return colorScheme
+	}
+	return defaultCompiledColorScheme
+}
+
+// displayTimestamp renders the timestamp of the colored layout.
+func (f *TextFormatter) displayTimestamp(t time.Time) string {
+	if !f.ShortTimestamp {
+		return f.formatTimestamp(t, f.coloredTimestampFormat())
+	}
+	if f.HumanShortTimestamp {
+		return f.humanTS()
+	}
+	return f.miniTSText()
+}
+
+func (f *TextFormatter) timestampFormat() string {
+	if f.TimestampFormat == "" {
+		return time.Stamp
+	}
+	return f.TimestampFormat
+}
+
+// coloredTimestampFormat returns the layout of full timestamps in colored
+// output, which adds milliseconds to the default with TimestampMillis.
+func (f *TextFormatter) coloredTimestampFormat() string {
+	if f.TimestampMillis && f.TimestampFormat == "" {
+		return time.StampMilli
+	}
+	return f.timestampFormat()
+}
+
+func (f *TextFormatter) appendLineEnding(b *bytes.Buffer) {
+	if f.LineEnding != LineEndingNone {
+		b.WriteString(f.lineEnding())
+	}
+}
+
+// lineEnding returns the sequence separating lines. Continuation lines of a
+// multi-line entry are separated by "\n" even with LineEndingNone.
+func (f *TextFormatter) lineEnding() string {
+	if f.LineEnding == LineEndingCRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+func (f *TextFormatter) printSummary(b *bytes.Buffer, isColored bool) {
+	warnings := atomic.LoadUint64(&f.levelCounts[WarnLevel])
+	var errors uint64
+	for _, level := range []Level{ErrorLevel, FatalLevel, PanicLevel} {
+		errors += atomic.LoadUint64(&f.levelCounts[level])
 	}
 
-	if f.DisableTimestamp {
-		fmt.Fprintf(b, "%s%s %s%+5s%s%s "+messageFormat, ansi.LightBlack, reset, levelColor, levelText, reset, prefix, message)
+	summary := fmt.Sprintf("warnings: %d, errors: %d, duration: %s", warnings, errors, f.humanTS())
+	if isColored {
+		b.WriteString(f.compiledColorScheme().TimestampColor(summary))
 	} else {
-		if f.ShortTimestamp {
-			fmt.Fprintf(b, "%s[%04d]%s %s%+5s%s%s "+messageFormat, ansi.LightBlack, miniTS(), reset, levelColor, levelText, reset, prefix, message)
-		} else {
-			fmt.Fprintf(b, "%s[%s]%s %s%+5s%s%s "+messageFormat, ansi.LightBlack, entry.Time.Format(timestampFormat), reset, levelColor, levelText, reset, prefix, message)
+		b.WriteString(summary)
+	}
+}
+
+// levelLabel returns the padded label of level in colored output.
+func (f *TextFormatter) levelLabel(level Level) string {
+	width := f.LevelPadding
+	if width == 0 {
+		width = f.widestLevelLabel()
+	}
+	return padLeft(f.unpaddedLevelLabel(level), width)
+}
+
+func (f *TextFormatter) unpaddedLevelLabel(level Level) string {
+	if label, ok := f.LevelLabels[level]; ok {
+		return label
+	}
+	return strings.ToUpper(f.levelName(level))
+}
+
+// widestLevelLabel returns the width of the widest level label, so labels of
+// all levels line up, e.g. 7 for WARNING.
+func (f *TextFormatter) widestLevelLabel() int {
+	widest := 0
+	for level := PanicLevel; level <= TraceLevel; level++ {
+		if w := stringWidth(f.unpaddedLevelLabel(level)); w > widest {
+			widest = w
 		}
 	}
-	for _, k := range keys {
-		v := entry.Data[k]
-		fmt.Fprintf(b, " %s%s%s=%+v", levelColor, k, reset, v)
+	return widest
+}
+
+// levelName maps level to the lowercase name used in plain output. The colored
+// label is its uppercase form.
+func (f *TextFormatter) levelName(level Level) string {
+	if level == WarnLevel && f.WarnLabel == WarnLabelShort {
+		return "warn"
 	}
+	return level.String()
 }
 
-func needsQuoting(text string) bool {
+// needsQuoting tells whether a string value is quoted in plain mode. By
+// default anything but letters, digits, '-' and '.' is quoted; StrictLogfmt
+// quotes only what logfmt requires.
+func needsQuoting(text string, strict bool) bool {
+	if strict {
+		return breaksLogfmt(text)
+	}
 	for _, ch := range text {
 		if !((ch >= 'a' && ch <= 'z') ||
 			(ch >= 'A' && ch <= 'Z') ||
 			(ch >= '0' && ch <= '9') ||
 			ch == '-' || ch == '.') {
-			return false
+			return true
 		}
 	}
-	return true
+	return false
 }
 
-func extractPrefix(msg string) (string, string) {
-	prefix := ""
-	regex := regexp.MustCompile("^\\[(.*?)\\]")
-	if regex.MatchString(msg) {
-		match := regex.FindString(msg)
-		prefix, msg = match[1:len(match)-1], strings.TrimSpace(msg[len(match):])
+// breaksLogfmt tells whether text can't be written as a bare logfmt value:
+// it contains spaces, '=', quotes, control or invalid characters.
+func breaksLogfmt(text string) bool {
+	for _, ch := range text {
+		if ch <= ' ' || ch == '=' || ch == '"' || ch == utf8.RuneError || !unicode.IsPrint(ch) {
+			return true
+		}
 	}
-	return prefix, msg
+	return false
 }
 
-func (f *TextFormatter) appendKeyValue(b *bytes.Buffer, key string, value interface{}) {
-	b.WriteString(key)
-	b.WriteByte('=')
-
-	switch value := value.(type) {
-	case string:
-		if needsQuoting(value) {
-			b.WriteString(value)
-		} else {
-			fmt.Fprintf(b, "%q", value)
+// logfmtKey replaces the characters logfmt doesn't allow in keys with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(ch rune) rune {
+		if ch <= ' ' || ch == '=' || ch == '"' || ch == utf8.RuneError || !unicode.IsPrint(ch) {
+			return '_'
 		}
-	case error:
-		errmsg := value.Error()
-		if needsQuoting(errmsg) {
-			b.WriteString(errmsg)
-		} else {
-			fmt.Fprintf(b, "%q", value)
+		return ch
+	}, key)
+}
+
+// Patterns of the message prefix syntaxes, capturing the prefix.
+var prefixPatterns = map[PrefixSyntax]*regexp.Regexp{
+	PrefixSyntaxBrackets: regexp.MustCompile(`^\[(.*?)\]`),
+	PrefixSyntaxParens:   regexp.MustCompile(`^\((.*?)\)`),
+	PrefixSyntaxColon:    regexp.MustCompile(`^([^\s:]+):(?:\s|$)`),
+}
+
+// extractPrefix splits a leading prefix in PrefixSyntax off msg.
+func (f *TextFormatter) extractPrefix(msg string) (string, string) {
+	regex := prefixPatterns[f.PrefixSyntax]
+	if regex == nil {
+		return "", msg
+	}
+	match := regex.FindStringSubmatchIndex(msg)
+	if match == nil {
+		return "", msg
+	}
+	return msg[match[2]:match[3]], strings.TrimSpace(msg[match[1]:])
+}
+
+func (f *TextFormatter) syslogPriority(level Level) int {
+	facility := f.SyslogFacility
+	if facility == 0 {
+		facility = 1
+	}
+	return facility*8 + syslogSeverity(level)
+}
+
+func syslogSeverity(level Level) int {
+	switch level {
+	case PanicLevel, FatalLevel:
+		return 2
+	case ErrorLevel:
+		return 3
+	case WarnLevel:
+		return 4
+	case InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// levelNumber returns the level_num value of level, if enabled.
+func (f *TextFormatter) levelNumber(level Level) (int, bool) {
+	switch f.NumericLevel {
+	case NumericLevelLogrus:
+		return int(level), true
+	case NumericLevelSyslog:
+		return syslogSeverity(level), true
+	}
+	return 0, false
+}
+
+func (f *TextFormatter) normalize(s string) string {
+	if !f.AllowANSI {
+		s = stripANSIString(s)
+	}
+	if !f.NormalizeUnicode {
+		return s
+	}
+	return norm.NFC.String(strings.Replace(s, "\uFEFF", "", -1))
+}
+
+// processValue applies the value transformations shared by all encoders.
+func (f *TextFormatter) processValue(key string, value interface{}) interface{} {
+	value = formatValue(resolveValue(value))
+	if len(f.Redactors) > 0 {
+		value = f.redact(key, value)
+	}
+	value = f.formatTimeValue(value)
+	if f.UnitConventions {
+		value = applyUnitConventions(key, value)
+	}
+	return f.normalizeValue(value)
+}
+
+// formatTimeValue renders time.Time values with the timestamp layout instead
+// of their default representation including monotonic clock readings.
+func (f *TextFormatter) formatTimeValue(value interface{}) interface{} {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return value
 		}
+		t = *v
 	default:
-		fmt.Fprint(b, value)
+		return value
+	}
+
+	if f.TimeFieldLocation != nil {
+		t = t.In(f.TimeFieldLocation)
 	}
+	layout := f.TimeFieldFormat
+	if layout == "" {
+		layout = f.timestampFormat()
+	}
+	return t.Format(layout)
+}
 
-	b.WriteByte(' ')
+// valueText renders the value of field for colored output. Only strings and
+// errors are normalized up front, so the rendered text of Stringers, slices
+// and structs is sanitized here.
+func (f *TextFormatter) valueText(field Field) string {
+	return shortenValue(field, f.normalize(fmt.Sprintf("%+v", field.Value)))
 }
 
-func prefixFieldClashes(data logrus.Fields) {
-	_, ok := data["time"]
-	if ok {
-		data["fields.time"] = data["time"]
+func (f *TextFormatter) normalizeValue(value interface{}) interface{} {
+	if f.AllowANSI && !f.NormalizeUnicode {
+		return value
+	}
+	switch value := value.(type) {
+	case string:
+		return f.normalize(value)
+	case error:
+		// Errors are kept as such for ErrorTree and ErrorDetails unless
+		// their text changes.
+		if text := value.Error(); f.NormalizeUnicode || hasANSI(text) {
+			return f.normalize(text)
+		}
 	}
-	_, ok = data["msg"]
-	if ok {
-		data["fields.msg"] = data["msg"]
+	return value
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
 	}
-	_, ok = data["level"]
-	if ok {
-		data["fields.level"] = data["level"]
+	return false
+}
+
+// prefixFieldClash renames fields clashing with the reserved keys of JSON
+// output, as named by FieldMap, like logrus does. Text output keeps both.
+func (f *TextFormatter) prefixFieldClash(key string) string {
+	switch key {
+	case f.FieldMap.resolve(FieldKeyTime), f.FieldMap.resolve(FieldKeyMsg), f.FieldMap.resolve(FieldKeyLevel), f.FieldMap.resolve(FieldKeyPrefix):
+		return "fields." + key
 	}
+	return key
 }
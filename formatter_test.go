@@ -0,0 +1,54 @@
+package prefixed
+
+import "testing"
+
+func TestNeedsQuoting(t *testing.T) {
+	for _, tt := range []struct {
+		text          string
+		quote, strict bool
+	}{
+		{"", false, false},
+		{"abc-1.2", false, false},
+		{"hello world", true, true},
+		{"a=b", true, true},
+		{`say "hi"`, true, true},
+		{`it's`, true, false},
+		{"/var/log", true, false},
+		{"tab\there", true, true},
+		{"line\nbreak", true, true},
+		{"bell\a", true, true},
+		{"\x1b[31m", true, true},
+		{"invalid\xff", true, true},
+		{"héllo", true, false},
+	} {
+		if got := needsQuoting(tt.text, false); got != tt.quote {
+			t.Errorf("needsQuoting(%q, false) = %v, want %v", tt.text, got, tt.quote)
+		}
+		if got := needsQuoting(tt.text, true); got != tt.strict {
+			t.Errorf("needsQuoting(%q, true) = %v, want %v", tt.text, got, tt.strict)
+		}
+	}
+}
+
+func TestPlainQuoting(t *testing.T) {
+	for _, tt := range []struct {
+		value interface{}
+		want  string
+	}{
+		{"", "level=info msg=done v= \n"},
+		{"ok", "level=info msg=done v=ok \n"},
+		{"two words", "level=info msg=done v=\"two words\" \n"},
+		{"k=v", "level=info msg=done v=\"k=v\" \n"},
+		{`"quoted"`, "level=info msg=done v=\"\\\"quoted\\\"\" \n"},
+		{"a\x00b", "level=info msg=done v=\"a\\x00b\" \n"},
+	} {
+		f := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+		out, err := f.format(&logEntry{level: InfoLevel, message: "done", data: map[string]interface{}{"v": tt.value}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(out); got != tt.want {
+			t.Errorf("v=%q: got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/x-cray/logrus-prefixed-formatter
+
+go 1.18
+
+require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/Sirupsen/logrus v0.11.5
+	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/text v0.3.8
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.15 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
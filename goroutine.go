@@ -0,0 +1,27 @@
+package prefixed
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// goroutineID returns the ID of the current goroutine, parsed from the
+// header of its stack trace: "goroutine 123 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if space := bytes.IndexByte(b, ' '); space >= 0 {
+		b = b[:space]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+func (f *TextFormatter) goroutineID() uint64 {
+	if f.GoroutineIDFunc != nil {
+		return f.GoroutineIDFunc()
+	}
+	return goroutineID()
+}
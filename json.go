@@ -0,0 +1,124 @@
+package prefixed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JSONFormatter renders entries as JSON objects. Like TextFormatter, it
+// extracts a [prefix] from the message unless a prefix field is set, and
+// emits it as a dedicated prefix key. Options must be set before the first
+// entry is formatted.
+type JSONFormatter struct {
+	// Timestamp format to use. Defaults to time.RFC3339.
+	TimestampFormat string
+
+	// Render timestamps in UTC instead of the local time zone.
+	TimestampUTC bool
+
+	// Fractional seconds of timestamps, replacing those of TimestampFormat.
+	TimestampPrecision TimestampPrecision
+
+	// Render timestamps as a Unix time number in the given unit instead.
+	TimestampEpoch EpochUnit
+
+	// Syntax of prefixes extracted from messages. Defaults to
+	// PrefixSyntaxBrackets.
+	PrefixSyntax PrefixSyntax
+
+	// Disable the time key.
+	DisableTimestamp bool
+
+	// FieldMap allows users to customize the names of keys for the reserved
+	// fields, e.g. {FieldKeyTime: "@timestamp"}.
+	FieldMap FieldMap
+
+	once sync.Once
+	text *TextFormatter
+}
+
+func (f *JSONFormatter) format(entry *logEntry) ([]byte, error) {
+	f.once.Do(func() {
+		timestampFormat := f.TimestampFormat
+		if timestampFormat == "" {
+			timestampFormat = time.RFC3339
+		}
+		f.text = &TextFormatter{
+			DisableColors:      true,
+			DisableTimestamp:   f.DisableTimestamp,
+			TimestampFormat:    timestampFormat,
+			TimestampUTC:       f.TimestampUTC,
+			TimestampPrecision: f.TimestampPrecision,
+			TimestampEpoch:     f.TimestampEpoch,
+			PrefixSyntax:       f.PrefixSyntax,
+			FieldMap:           f.FieldMap,
+			EmptyMessage:       EmptyMessageQuoted,
+			// Keys are sorted when marshaling the map.
+			SortingFunc: func([]string) {},
+		}
+		f.text.Encoder = &jsonEncoder{f.text}
+	})
+	return f.text.format(entry)
+}
+
+// JSONKey is a reserved field rendering a single entry as one-line JSON even
+// in text mode, e.g. for entries scraped by machines out of human logs:
+//
+//	log.WithField(prefixed.JSONKey, true).WithField("orders", n).Info("Daily stats")
+const JSONKey = "@json"
+
+// isJSONEntry tells whether data requests JSON rendering.
+func isJSONEntry(data map[string]interface{}) bool {
+	v, ok := data[JSONKey]
+	return ok && v != false
+}
+
+// jsonEncoder renders a record as a single-line JSON object.
+type jsonEncoder struct {
+	f *TextFormatter
+}
+
+func (e *jsonEncoder) Encode(b *bytes.Buffer, r *Record) error {
+	data := make(map[string]interface{}, len(r.Fields)+5)
+	for _, field := range r.Fields {
+		if err, ok := field.Value.(error); ok {
+			// Otherwise errors are marshaled as empty objects.
+			data[e.f.prefixFieldClash(field.Key)] = err.Error()
+		} else {
+			data[e.f.prefixFieldClash(field.Key)] = field.Value
+		}
+	}
+
+	if !e.f.DisableTimestamp {
+		data[e.f.FieldMap.resolve(FieldKeyTime)] = e.f.plainTimestamp(r.Time)
+	}
+	data[e.f.FieldMap.resolve(FieldKeyLevel)] = r.Level.String()
+	if num, ok := e.f.levelNumber(r.Level); ok {
+		data["level_num"] = num
+	}
+	if r.Prefix != "" {
+		data[e.f.FieldMap.resolve(FieldKeyPrefix)] = r.Prefix
+	}
+	if r.Message != "" || e.f.EmptyMessage != EmptyMessageOmit {
+		data[e.f.FieldMap.resolve(FieldKeyMsg)] = r.Message
+	}
+	if r.ID != "" {
+		data["id"] = r.ID
+	}
+	if r.Caller != nil {
+		data["caller"] = e.f.callerText(r.Caller)
+		if e.f.CallerFunction {
+			data["func"] = e.f.functionName(r.Caller)
+		}
+	}
+
+	serialized, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fields to JSON, %v", err)
+	}
+	b.Write(serialized)
+	return nil
+}
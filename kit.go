@@ -0,0 +1,62 @@
+package prefixed
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// KitLogger implements go-kit's log.Logger interface on top of the formatter,
+// so components logging through go-kit produce the same output as logrus
+// ones. The "msg" keyval becomes the entry message, "prefix" the entry prefix
+// and "level" the entry level; all other keyvals are rendered as fields.
+type KitLogger struct {
+	formatter *TextFormatter
+	out       io.Writer
+	mu        sync.Mutex
+}
+
+// NewKitLogger returns a go-kit logger writing entries formatted by f to w.
+func NewKitLogger(w io.Writer, f *TextFormatter) *KitLogger {
+	return &KitLogger{formatter: f, out: w}
+}
+
+func (l *KitLogger) Log(keyvals ...interface{}) error {
+	entry := &logEntry{
+		time:  l.formatter.now(),
+		level: InfoLevel,
+		data:  make(map[string]interface{}, len(keyvals)/2),
+		out:   l.out,
+	}
+
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		switch key {
+		case "msg":
+			entry.message = fmt.Sprint(value)
+		case "level":
+			if level, ok := parseLevel(fmt.Sprint(value)); ok {
+				entry.level = level
+			} else {
+				entry.data[key] = value
+			}
+		default:
+			entry.data[key] = value
+		}
+	}
+
+	b, err := l.formatter.format(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.out.Write(b)
+	return err
+}
@@ -0,0 +1,62 @@
+package prefixed
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// layoutSections holds the rendered, colored sections of an entry that are
+// available to LayoutTemplate.
+type layoutSections struct {
+	Timestamp string
+	Level     string
+	Prefix    string
+	Message   string
+	Caller    string
+	Fields    string
+	ID        string
+}
+
+// layout returns the compiled LayoutTemplate, or nil if none is set. The
+// template is compiled once, on first use.
+func (f *TextFormatter) layout() (*template.Template, error) {
+	f.layoutOnce.Do(func() {
+		if f.LayoutTemplate != "" {
+			f.layoutTemplate, f.layoutErr = template.New("layout").Parse(f.LayoutTemplate)
+		}
+	})
+	return f.layoutTemplate, f.layoutErr
+}
+
+// headlineWidth returns the visible width of the sections preceding the
+// message in the default layout.
+func (s *layoutSections) headlineWidth() int {
+	width := visibleWidth(s.Timestamp)
+	if s.Level != "" {
+		width += visibleWidth(s.Level) + 2
+	}
+	if s.Prefix != "" {
+		width += visibleWidth(s.Prefix) + 1
+	}
+	return width
+}
+
+func (s *layoutSections) writeDefault(b *bytes.Buffer) {
+	if s.Timestamp != "" {
+		b.WriteString(s.Timestamp)
+	}
+	if s.Level != "" {
+		b.WriteString(" " + s.Level + " ")
+	}
+	if s.Prefix != "" {
+		b.WriteString(s.Prefix + " ")
+	}
+	b.WriteString(s.Message)
+	if s.Caller != "" {
+		b.WriteString(" " + s.Caller)
+	}
+	b.WriteString(s.Fields)
+	if s.ID != "" {
+		b.WriteString(" " + s.ID)
+	}
+}
@@ -0,0 +1,72 @@
+package prefixed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level mirrors the logrus severity levels, so the formatter does not depend
+// on the logrus import path in use.
+type Level uint32
+
+const (
+	PanicLevel Level = iota
+	FatalLevel
+	ErrorLevel
+	WarnLevel
+	InfoLevel
+	DebugLevel
+	TraceLevel
+)
+
+func (level Level) String() string {
+	switch level {
+	case TraceLevel:
+		return "trace"
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warning"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	case PanicLevel:
+		return "panic"
+	}
+	return "unknown"
+}
+
+// ParseLevel converts a level name such as "info", "warn" or "err" into a
+// Level.
+func ParseLevel(name string) (Level, error) {
+	level, ok := parseLevel(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown level %q", name)
+	}
+	return level, nil
+}
+
+// parseLevel converts a level name as commonly written by logging libraries
+// into a Level.
+func parseLevel(name string) (Level, bool) {
+	switch strings.ToLower(name) {
+	case "panic":
+		return PanicLevel, true
+	case "fatal", "crit", "critical":
+		return FatalLevel, true
+	case "error", "err":
+		return ErrorLevel, true
+	case "warn", "warning":
+		return WarnLevel, true
+	case "info":
+		return InfoLevel, true
+	case "debug":
+		return DebugLevel, true
+	case "trace":
+		return TraceLevel, true
+	}
+	return 0, false
+}
@@ -0,0 +1,65 @@
+package prefixed
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mgutz/ansi"
+)
+
+func traceEntry() *logrusEntry {
+	return &logrusEntry{
+		Level:   logrusLevel(TraceLevel),
+		Message: "row decoded",
+		Data:    map[string]interface{}{"id": 7},
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	t.Run("colored", func(t *testing.T) {
+		for _, tt := range []struct {
+			padding   int
+			warnLabel WarnLabel
+			label     string
+		}{
+			{0, WarnLabelLong, "  TRACE"},
+			{0, WarnLabelShort, "TRACE"},
+			{7, WarnLabelShort, "  TRACE"},
+		} {
+			f := &TextFormatter{ForceColors: true, DisableTimestamp: true, LevelPadding: tt.padding, WarnLabel: tt.warnLabel}
+			out, err := f.Format(traceEntry())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := ansi.Color(tt.label, "magenta"); !strings.Contains(string(out), want) {
+				t.Errorf("LevelPadding %d: %q does not contain %q", tt.padding, out, want)
+			}
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		f := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+		out, err := f.Format(traceEntry())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(out), "level=trace msg=\"row decoded\" id=7 \n"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := (&JSONFormatter{}).Format(traceEntry())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(out, &data); err != nil {
+			t.Fatal(err)
+		}
+		if data["level"] != "trace" {
+			t.Errorf("level = %v, want trace", data["level"])
+		}
+	})
+}
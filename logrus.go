@@ -0,0 +1,50 @@
+package prefixed
+
+// The logrus import path is selected by build tag in logrus_uppercase.go and
+// logrus_lowercase.go, which map logrus entries to logEntry values. The go
+// tool rejects builds importing both paths as a case-insensitive import
+// collision, so each build has a single path. The adapters below are shared
+// by both.
+
+// Format renders a single logrus.Entry.
+func (f *TextFormatter) Format(entry *logrusEntry) ([]byte, error) {
+	e := newLogEntry(entry)
+	if e.buffer != nil {
+		return f.format(e)
+	}
+	return f.appendFormat(nil, e)
+}
+
+// AppendFormat appends the formatted entry to dst and returns the extended
+// slice, like Format but without allocating the output in hot paths that
+// reuse dst. The buffer of entry, if any, is left untouched.
+func (f *TextFormatter) AppendFormat(dst []byte, entry *logrusEntry) ([]byte, error) {
+	return f.appendFormat(dst, newLogEntry(entry))
+}
+
+// Format renders a single logrus.Entry as a JSON object.
+func (f *JSONFormatter) Format(entry *logrusEntry) ([]byte, error) {
+	return f.format(newLogEntry(entry))
+}
+
+// Fallback delegates the rendering of entries whose prefix satisfies match
+// to other, e.g. prefixes still owned by a team using a legacy formatter,
+// and returns f. A nil match delegates every entry.
+func (f *TextFormatter) Fallback(other logrusFormatter, match func(prefix string) bool) *TextFormatter {
+	if match == nil {
+		match = func(string) bool { return true }
+	}
+	f.fallbackMatch = match
+	f.fallback = func(entry *logEntry) ([]byte, error) {
+		return other.Format(entry.source.(*logrusEntry))
+	}
+	return f
+}
+
+func (s *LevelSplitter) Levels() []logrusLevel {
+	return logrusAllLevels
+}
+
+func (s *LevelSplitter) Fire(entry *logrusEntry) error {
+	return s.write(newLogEntry(entry))
+}
@@ -0,0 +1,49 @@
+//go:build !logrus_uppercase
+// +build !logrus_uppercase
+
+package prefixed
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	logrusEntry     = logrus.Entry
+	logrusFormatter = logrus.Formatter
+	logrusLevel     = logrus.Level
+)
+
+var logrusAllLevels = logrus.AllLevels
+
+func newLogEntry(entry *logrus.Entry) *logEntry {
+	e := &logEntry{
+		time:    entry.Time,
+		level:   Level(entry.Level),
+		message: entry.Message,
+		data:    entry.Data,
+		source:  entry,
+		context: entry.Context,
+		buffer:  entry.Buffer,
+	}
+	if entry.Logger != nil {
+		e.out = entry.Logger.Out
+	}
+	if entry.HasCaller() {
+		e.caller = entry.Caller
+	}
+	return e
+}
+
+// Newer logrus versions no longer export their terminal check, so fall back
+// to detecting character devices.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,36 @@
+//go:build logrus_uppercase
+// +build logrus_uppercase
+
+package prefixed
+
+import (
+	"io"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type (
+	logrusEntry     = logrus.Entry
+	logrusFormatter = logrus.Formatter
+	logrusLevel     = logrus.Level
+)
+
+var logrusAllLevels = logrus.AllLevels
+
+func newLogEntry(entry *logrus.Entry) *logEntry {
+	e := &logEntry{
+		time:    entry.Time,
+		level:   Level(entry.Level),
+		message: entry.Message,
+		data:    entry.Data,
+		source:  entry,
+	}
+	if entry.Logger != nil {
+		e.out = entry.Logger.Out
+	}
+	return e
+}
+
+func isTerminal(w io.Writer) bool {
+	return logrus.IsTerminal(w)
+}
@@ -0,0 +1,69 @@
+package prefixed
+
+import "time"
+
+// FieldMergePolicy decides which value is kept when static, context and
+// entry fields share a key.
+type FieldMergePolicy int
+
+const (
+	// MergeEntryWins keeps entry fields over context fields over static
+	// fields. This is the default.
+	MergeEntryWins FieldMergePolicy = iota
+	// MergeStaticWins keeps static fields over context fields over entry
+	// fields.
+	MergeStaticWins
+	// MergeSuffixDuplicates keeps every value: entry fields keep their key,
+	// while colliding context and static fields are renamed to key.context
+	// and key.static.
+	MergeSuffixDuplicates
+)
+
+type fieldLayer struct {
+	suffix string
+	fields map[string]interface{}
+}
+
+// mergeFields returns entry with the StaticFields and the fields of the
+// ContextExtractors merged into its own according to FieldMergePolicy. All
+// output modes get their fields from this merge.
+func (f *TextFormatter) mergeFields(entry *logEntry) *logEntry {
+	var contextFields map[string]interface{}
+	if entry.context != nil {
+		for _, extract := range f.ContextExtractors {
+			for k, v := range extract(entry.context) {
+				if contextFields == nil {
+					contextFields = make(map[string]interface{})
+				}
+				if deadline, ok := v.(contextDeadline); ok {
+					v = time.Time(deadline).Sub(f.now())
+				}
+				contextFields[k] = v
+			}
+		}
+	}
+	if len(contextFields) == 0 && len(f.StaticFields) == 0 {
+		return entry
+	}
+
+	// Layers in order of precedence.
+	layers := []fieldLayer{{"", entry.data}, {"context", contextFields}, {"static", f.StaticFields}}
+	if f.FieldMergePolicy == MergeStaticWins {
+		layers[0], layers[2] = layers[2], layers[0]
+	}
+
+	merged := make(map[string]interface{}, len(entry.data)+len(contextFields)+len(f.StaticFields))
+	for _, layer := range layers {
+		for k, v := range layer.fields {
+			if _, taken := merged[k]; !taken {
+				merged[k] = v
+			} else if f.FieldMergePolicy == MergeSuffixDuplicates {
+				merged[k+"."+layer.suffix] = v
+			}
+		}
+	}
+
+	e := *entry
+	e.data = merged
+	return &e
+}
@@ -0,0 +1,21 @@
+package prefixed
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// processMetadata returns the host, pid and exe fields injected with
+// ProcessMetadata, computed on first use.
+func (f *TextFormatter) processMetadata() []Field {
+	f.metadataOnce.Do(func() {
+		host, _ := os.Hostname()
+		f.metadata = []Field{{Key: "host", Value: host}, {Key: "pid", Value: os.Getpid()}}
+		if f.ProcessExe {
+			if exe, err := os.Executable(); err == nil {
+				f.metadata = append(f.metadata, Field{Key: "exe", Value: filepath.Base(exe)})
+			}
+		}
+	})
+	return f.metadata
+}
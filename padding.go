@@ -0,0 +1,111 @@
+package prefixed
+
+import (
+	"strings"
+	"sync"
+)
+
+// PrefixPaddingAuto pads prefixes to the widest prefix seen so far.
+const PrefixPaddingAuto = -1
+
+// Default of MaxAutoPadding.
+const defaultMaxAutoPadding = 40
+
+// widthTracker learns the widest section seen so far, partitioned by key,
+// e.g. per prefix, so one component doesn't force its widths onto others.
+type widthTracker struct {
+	mu     sync.Mutex
+	widths map[string]int
+}
+
+// learn records width under key and returns the widest width seen for key.
+// Widths beyond max are not learned, so a single outlier can't force absurd
+// padding onto later entries.
+func (t *widthTracker) learn(key string, width, max int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.widths == nil {
+		t.widths = make(map[string]int)
+	}
+	if width <= max && width > t.widths[key] {
+		t.widths[key] = width
+	}
+	return t.widths[key]
+}
+
+// peek returns what learn would return, without recording width.
+func (t *widthTracker) peek(key string, width, max int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if width <= max && width > t.widths[key] {
+		return width
+	}
+	return t.widths[key]
+}
+
+// Columns SpacePaddingAuto keeps free for the fields of an entry.
+const autoPaddingFieldRoom = 20
+
+// maxAutoPadding returns the widest padding learned by automatic alignment.
+func (f *TextFormatter) maxAutoPadding() int {
+	if f.MaxAutoPadding > 0 {
+		return f.MaxAutoPadding
+	}
+	return defaultMaxAutoPadding
+}
+
+// padPrefix pads the rendered prefix section to the PrefixPadding column.
+// Entries without prefix are padded too, so messages line up. Unless learn
+// is set, the prefix width is not recorded.
+func (f *TextFormatter) padPrefix(prefix string, learn bool) string {
+	if f.PrefixPadding == 0 {
+		return prefix
+	}
+
+	column := f.PrefixPadding
+	if column == PrefixPaddingAuto {
+		// All prefixes share the column the messages start at.
+		widest := f.prefixWidths.learn
+		if !learn {
+			widest = f.prefixWidths.peek
+		}
+		column = widest("", visibleWidth(prefix), f.maxAutoPadding())
+	}
+	return padRight(prefix, column)
+}
+
+// autoPadMessage pads the message section to the widest message seen for
+// prefix, truncating it to the columns the terminal has left after the
+// headline and, if withFields, some room for fields. Unless learn is set,
+// the message width is not recorded.
+func (f *TextFormatter) autoPadMessage(s *layoutSections, t *terminal, prefix string, withFields, learn bool) string {
+	message := s.Message
+	if strings.Contains(message, "\n") {
+		return message
+	}
+
+	available := t.currentWidth() - s.headlineWidth()
+	if withFields {
+		available -= autoPaddingFieldRoom
+	}
+	if available <= 0 {
+		return message
+	}
+	width := visibleWidth(message)
+	if width > available && !hasANSI(message) {
+		// Truncated messages keep their full width, so they aren't learned.
+		message = truncateWidth(message, available)
+	}
+
+	max := f.maxAutoPadding()
+	if available < max {
+		max = available
+	}
+	widest := f.messageWidths.learn
+	if !learn {
+		widest = f.messageWidths.peek
+	}
+	return padRight(message, widest(prefix, width, max))
+}
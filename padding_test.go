@@ -0,0 +1,140 @@
+package prefixed
+
+import (
+	"strings"
+	"testing"
+)
+
+// offStyles renders the colored layout without colors, as the uncolored
+// reference. DisableColors itself switches to logfmt, which has no columns.
+var offStyles = &ColorScheme{
+	InfoLevelStyle:  "off",
+	WarnLevelStyle:  "off",
+	ErrorLevelStyle: "off",
+	FatalLevelStyle: "off",
+	PanicLevelStyle: "off",
+	DebugLevelStyle: "off",
+	TraceLevelStyle: "off",
+	PrefixStyle:     "off",
+	TimestampStyle:  "off",
+	CallerStyle:     "off",
+	FieldKeyStyle:   "off",
+	FieldValueStyle: "off",
+}
+
+func TestColoredAlignment(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       *TextFormatter
+		level   Level
+		message string
+		data    map[string]interface{}
+		columns []string // text whose visible column must match
+	}{
+		{
+			name:    "space padding",
+			f:       &TextFormatter{SpacePadding: 30},
+			level:   InfoLevel,
+			message: "[db] query finished",
+			data:    map[string]interface{}{"rows": 3},
+			columns: []string{"query", "rows="},
+		},
+		{
+			name:    "prefix padding",
+			f:       &TextFormatter{PrefixPadding: 12},
+			level:   WarnLevel,
+			message: "[api] slow request",
+			data:    map[string]interface{}{"ms": 950},
+			columns: []string{"slow", "ms="},
+		},
+		{
+			name:    "level padding",
+			f:       &TextFormatter{LevelPadding: 7},
+			level:   ErrorLevel,
+			message: "disk full",
+			columns: []string{"disk"},
+		},
+		{
+			name:    "wide message",
+			f:       &TextFormatter{SpacePadding: 20},
+			level:   InfoLevel,
+			message: "[ui] 日本語のメッセージ",
+			data:    map[string]interface{}{"lang": "ja"},
+			columns: []string{"日本語", "lang="},
+		},
+		{
+			name:    "colored values",
+			f:       &TextFormatter{SpacePadding: 24, CorrelationKeys: []string{"request"}},
+			level:   DebugLevel,
+			message: "[db] cache miss",
+			data:    map[string]interface{}{"request": "r-42", "key": "user:7"},
+			columns: []string{"cache", "key=", "request="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			render := func(scheme *ColorScheme) string {
+				f := tt.f.Clone()
+				f.ForceColors = true
+				f.DisableTimestamp = true
+				if scheme != nil {
+					if err := f.SetColorScheme(scheme); err != nil {
+						t.Fatal(err)
+					}
+				}
+				out, err := f.format(&logEntry{level: tt.level, message: tt.message, data: tt.data})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return stripANSIString(string(out))
+			}
+
+			colored, plain := render(nil), render(offStyles)
+			for _, text := range tt.columns {
+				if got, want := visibleColumn(t, colored, text), visibleColumn(t, plain, text); got != want {
+					t.Errorf("%q at column %d, want %d", text, got, want)
+				}
+			}
+		})
+	}
+}
+
+// visibleColumn returns the terminal column text starts at in line.
+func visibleColumn(t *testing.T, line, text string) int {
+	t.Helper()
+	i := strings.Index(line, text)
+	if i < 0 {
+		t.Fatalf("%q not found in %q", text, line)
+	}
+	return stringWidth(line[:i])
+}
+
+func TestLevelLabelAlignment(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		f    *TextFormatter
+	}{
+		{"long warning label", &TextFormatter{}},
+		{"short warning label", &TextFormatter{WarnLabel: WarnLabelShort}},
+		{"custom labels", &TextFormatter{LevelLabels: map[Level]string{InfoLevel: "I", WarnLevel: "ATTENTION"}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.f.ForceColors = true
+			tt.f.DisableTimestamp = true
+			want := -1
+			for level := PanicLevel; level <= TraceLevel; level++ {
+				out, err := tt.f.format(&logEntry{level: level, message: "[db] query finished"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := visibleColumn(t, stripANSIString(string(out)), "db:")
+				if want < 0 {
+					want = got
+				} else if got != want {
+					t.Errorf("%s: prefix at column %d, want %d", level, got, want)
+				}
+			}
+		})
+	}
+}
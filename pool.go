@@ -0,0 +1,42 @@
+package prefixed
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Buffers larger than this are dropped instead of being pooled, so a single
+// huge entry doesn't pin its memory for the lifetime of the process.
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	b := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(b)
+	}
+}
+
+// appendFormat formats entry into a pooled scratch buffer, replacing any
+// buffer of the entry, and appends the result to dst. Unless an encoder or
+// hook retains it, no memory is allocated for the output itself once dst
+// is large enough.
+func (f *TextFormatter) appendFormat(dst []byte, entry *logEntry) ([]byte, error) {
+	b := getBuffer()
+	defer putBuffer(b)
+
+	entry.buffer = b
+	out, err := f.format(entry)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, out...), nil
+}
@@ -0,0 +1,111 @@
+package prefixed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate reports every style of PrefixColors and PrefixComponentStyles
+// that is not valid ansi syntax. Invalid styles are ignored when rendering.
+func (f *TextFormatter) Validate() error {
+	prefixes := make([]string, 0, len(f.PrefixColors))
+	for prefix := range f.PrefixColors {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var invalid []string
+	for _, prefix := range prefixes {
+		if style := f.PrefixColors[prefix]; !validStyle(style) {
+			invalid = append(invalid, invalidStyle(fmt.Sprintf("PrefixColors[%q]", prefix), style))
+		}
+	}
+	for i, style := range f.PrefixComponentStyles {
+		if !validStyle(style) {
+			invalid = append(invalid, invalidStyle(fmt.Sprintf("PrefixComponentStyles[%d]", i), style))
+		}
+	}
+	return invalidStylesError(invalid)
+}
+
+// prefixColor returns the color of prefix: its PrefixColors style, a color
+// derived from its hash with AutoPrefixColors, or the scheme's PrefixStyle.
+func (f *TextFormatter) prefixColor(prefix string, colors *compiledColorScheme) func(string) string {
+	if style, ok := f.PrefixColors[prefix]; ok {
+		if color := f.styleColor(style); color != nil {
+			return color
+		}
+	}
+	if f.AutoPrefixColors {
+		return f.hashColor(prefix)
+	}
+	return colors.PrefixColor
+}
+
+// styleColor compiles style, caching the result. It returns nil for styles
+// failing validStyle, whose callers fall back to their default color.
+func (f *TextFormatter) styleColor(style string) func(string) string {
+	if color, ok := f.prefixColorCache.Load(style); ok {
+		return color.(func(string) string)
+	}
+	var color func(string) string
+	if validStyle(style) {
+		color = styleColorFunc(style, terminalColorDepth())
+	}
+	f.prefixColorCache.Store(style, color)
+	return color
+}
+
+func (f *TextFormatter) prefixDelimiter() string {
+	if f.PrefixDelimiter == "" {
+		return "/"
+	}
+	return f.PrefixDelimiter
+}
+
+// renderPrefix renders the colored "prefix:" headline section, styling the
+// components of hierarchical prefixes and keeping the last PrefixDepth ones.
+func (f *TextFormatter) renderPrefix(prefix string, colors *compiledColorScheme) string {
+	color := f.prefixColor(prefix, colors)
+	if len(f.PrefixComponentStyles) == 0 && f.PrefixDepth <= 0 {
+		return color(truncateMiddle(prefix, f.MaxPrefixLength) + ":")
+	}
+
+	delimiter := f.prefixDelimiter()
+	components := strings.Split(prefix, delimiter)
+	first := 0
+	if f.PrefixDepth > 0 && len(components) > f.PrefixDepth {
+		first = len(components) - f.PrefixDepth
+	}
+	if f.MaxPrefixLength > 0 {
+		shown := strings.Join(components[first:], delimiter)
+		if first > 0 {
+			shown = "…" + delimiter + shown
+		}
+		if utf8.RuneCountInString(shown) > f.MaxPrefixLength {
+			// Components can't be styled independently once cut.
+			return color(truncateMiddle(shown, f.MaxPrefixLength) + ":")
+		}
+	}
+
+	var b strings.Builder
+	if first > 0 {
+		b.WriteString(color("…" + delimiter))
+	}
+	for i := first; i < len(components); i++ {
+		if i > first {
+			b.WriteString(color(delimiter))
+		}
+		componentColor := color
+		if i < len(f.PrefixComponentStyles) && f.PrefixComponentStyles[i] != "" {
+			if style := f.styleColor(f.PrefixComponentStyles[i]); style != nil {
+				componentColor = style
+			}
+		}
+		b.WriteString(componentColor(components[i]))
+	}
+	b.WriteString(color(":"))
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package prefixed
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePrefixStyles(t *testing.T) {
+	f := &TextFormatter{PrefixColors: map[string]string{"api": "green", "db": "gren+h"}}
+	err := f.Validate()
+	if err == nil || !strings.Contains(err.Error(), `PrefixColors["db"] "gren+h"`) {
+		t.Fatalf("got %v, want an error naming the db style", err)
+	}
+	if strings.Contains(err.Error(), "api") {
+		t.Errorf("valid style reported: %v", err)
+	}
+
+	// The invalid style falls back to the scheme's prefix color.
+	f.ForceColors = true
+	f.DisableTimestamp = true
+	got, err := f.format(&logEntry{level: InfoLevel, message: "[db] connected"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := (&TextFormatter{ForceColors: true, DisableTimestamp: true}).format(&logEntry{level: InfoLevel, message: "[db] connected"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestValidatePrefixComponentStyles(t *testing.T) {
+	f := &TextFormatter{PrefixComponentStyles: []string{"cyan", "bleu", "magenta+b"}}
+	err := f.Validate()
+	if err == nil || !strings.Contains(err.Error(), `PrefixComponentStyles[1] "bleu"`) {
+		t.Fatalf("got %v, want an error naming component 1", err)
+	}
+	if strings.Contains(err.Error(), "[0]") || strings.Contains(err.Error(), "[2]") {
+		t.Errorf("valid styles reported: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package prefixedconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+// FromEnv returns a formatter configured from environment variables, for
+// per-deployment tuning in containers. LOG_CONFIG names a config file read
+// with Load first, and the other variables override its options:
+//
+//	LOG_COLORS            always, never or auto (the default)
+//	LOG_TIMESTAMP_FORMAT  layout of full timestamps
+//	LOG_FULL_TIMESTAMP    false for timestamps relative to process start
+//	LOG_DISABLE_TIMESTAMP true to omit timestamps
+//	LOG_TIMESTAMP_UTC     true for timestamps in UTC
+//	LOG_PREFIX_PADDING    prefix column width, or auto
+//	LOG_MIN_LEVEL         least severe level rendered, e.g. info
+//	LOG_COMPACT           true for CompactMode
+//
+// Booleans accept the values of strconv.ParseBool. Invalid values are
+// reported as errors naming the variable.
+func FromEnv() (*prefixed.TextFormatter, error) {
+	f := &prefixed.TextFormatter{}
+	if path := os.Getenv("LOG_CONFIG"); path != "" {
+		var err error
+		if f, err = Load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if value, ok := os.LookupEnv("LOG_COLORS"); ok {
+		switch strings.ToLower(value) {
+		case "always", "force":
+			f.ForceColors, f.DisableColors = true, false
+		case "never", "off":
+			f.ForceColors, f.DisableColors = false, true
+		case "auto", "":
+			f.ForceColors, f.DisableColors = false, false
+		default:
+			return nil, envError("LOG_COLORS", value)
+		}
+	}
+	if value, ok := os.LookupEnv("LOG_TIMESTAMP_FORMAT"); ok {
+		f.TimestampFormat = value
+	}
+	if err := envBool("LOG_FULL_TIMESTAMP", func(full bool) { f.ShortTimestamp = !full }); err != nil {
+		return nil, err
+	}
+	if err := envBool("LOG_DISABLE_TIMESTAMP", func(b bool) { f.DisableTimestamp = b }); err != nil {
+		return nil, err
+	}
+	if err := envBool("LOG_TIMESTAMP_UTC", func(b bool) { f.TimestampUTC = b }); err != nil {
+		return nil, err
+	}
+	if err := envBool("LOG_COMPACT", func(b bool) { f.CompactMode = b }); err != nil {
+		return nil, err
+	}
+	if value, ok := os.LookupEnv("LOG_PREFIX_PADDING"); ok {
+		if strings.ToLower(value) == "auto" {
+			f.PrefixPadding = prefixed.PrefixPaddingAuto
+		} else if padding, err := strconv.Atoi(value); err == nil && padding >= 0 {
+			f.PrefixPadding = padding
+		} else {
+			return nil, envError("LOG_PREFIX_PADDING", value)
+		}
+	}
+	if value, ok := os.LookupEnv("LOG_MIN_LEVEL"); ok {
+		level, err := prefixed.ParseLevel(value)
+		if err != nil {
+			return nil, envError("LOG_MIN_LEVEL", value)
+		}
+		f.MinLevel = &level
+	}
+	return f, nil
+}
+
+// envBool passes the boolean value of the environment variable name to set,
+// if the variable is set.
+func envBool(name string, set func(bool)) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return envError(name, value)
+	}
+	set(b)
+	return nil
+}
+
+func envError(name, value string) error {
+	return fmt.Errorf("%s: invalid value %q", name, value)
+}
@@ -0,0 +1,94 @@
+// Package prefixedconfig configures the prefixed formatter from config files
+// and environment variables, so log appearance can be tweaked without
+// recompiling. It is kept apart from the formatter, so programs configuring
+// it in code don't depend on the YAML and TOML decoders.
+package prefixedconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+	"gopkg.in/yaml.v2"
+)
+
+// Load reads formatter options from a YAML (.yaml, .yml), TOML (.toml) or
+// JSON (.json) file and returns a formatter configured with them. See
+// prefixed.FileConfig for the supported options.
+func Load(path string) (*prefixed.TextFormatter, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := parseFileConfig(data, strings.ToLower(filepath.Ext(path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	f, err := c.Formatter()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return f, nil
+}
+
+// parseFileConfig decodes data in the format of ext. YAML and TOML are
+// converted to JSON first, so all formats match keys the same way.
+func parseFileConfig(data []byte, ext string) (*prefixed.FileConfig, error) {
+	switch ext {
+	case ".json":
+	case ".yaml", ".yml":
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		converted, err := json.Marshal(jsonCompatible(doc))
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	case ".toml":
+		var doc map[string]interface{}
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, err
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", ext)
+	}
+
+	c := &prefixed.FileConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// jsonCompatible converts the map[interface{}]interface{} values produced by
+// the YAML decoder into maps JSON can encode.
+func jsonCompatible(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[fmt.Sprint(k)] = jsonCompatible(v)
+		}
+		return m
+	case []interface{}:
+		for i, v := range value {
+			value[i] = jsonCompatible(v)
+		}
+	}
+	return value
+}
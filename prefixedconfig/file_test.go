@@ -0,0 +1,37 @@
+package prefixedconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRejectsInvalidPrefixStyles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prefixedconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"prefix colors", "PrefixColors: {api: green, db: gren+h}\n", `PrefixColors["db"] "gren+h"`},
+		{"component styles", "PrefixComponentStyles: [cyan, bleu]\n", `PrefixComponentStyles[1] "bleu"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "log.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %s", err, tt.want)
+			}
+		})
+	}
+}
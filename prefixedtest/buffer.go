@@ -0,0 +1,56 @@
+package prefixedtest
+
+import (
+	"bytes"
+	"errors"
+)
+
+// CheckBufferOwnership formats copies of entry with f and returns an error
+// unless f honors the ownership of the buffers involved:
+//
+//   - Output returned without a logger-provided Buffer must not be changed
+//     by later calls, i.e. scratch buffers must not leak to the caller.
+//   - With a Buffer, taken from entry or allocated if entry has none, the
+//     Buffer must not be replaced and the output must be written to it,
+//     aliasing its contents rather than a separate allocation.
+//
+// The second check is skipped for logrus versions without Entry.Buffer.
+func CheckBufferOwnership(f logrusFormatter, entry *logrusEntry) error {
+	e := *entry
+	setBuffer(&e, nil)
+	first, err := f.Format(&e)
+	if err != nil {
+		return err
+	}
+	saved := append([]byte(nil), first...)
+	if _, err := f.Format(&e); err != nil {
+		return err
+	}
+	if !bytes.Equal(first, saved) {
+		return errors.New("formatter output was overwritten by a later call")
+	}
+
+	buffer := entryBuffer(entry)
+	if buffer == nil {
+		buffer = &bytes.Buffer{}
+	}
+	e = *entry
+	if !setBuffer(&e, buffer) {
+		return nil
+	}
+
+	out, err := f.Format(&e)
+	if err != nil {
+		return err
+	}
+	if entryBuffer(&e) != buffer {
+		return errors.New("formatter replaced entry.Buffer")
+	}
+	if !bytes.Equal(out, buffer.Bytes()) {
+		return errors.New("formatter output differs from entry.Buffer contents")
+	}
+	if len(out) > 0 && &out[0] != &buffer.Bytes()[0] {
+		return errors.New("formatter output does not alias entry.Buffer")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package prefixedtest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+// buffers hands out entry buffers the way logrus does: pooled and reset,
+// but possibly retaining capacity from earlier entries.
+var buffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func TestCheckBufferOwnership(t *testing.T) {
+	formatters := []struct {
+		name      string
+		formatter logrusFormatter
+	}{
+		{"text", &prefixed.TextFormatter{DisableColors: true}},
+		{"colored", &prefixed.TextFormatter{ForceColors: true}},
+		{"json", &prefixed.JSONFormatter{}},
+	}
+
+	for _, tt := range formatters {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				buffer := buffers.Get().(*bytes.Buffer)
+				buffer.Reset()
+
+				entry := &logrusEntry{
+					Time:    Time,
+					Message: "[db] query finished",
+					Data:    map[string]interface{}{"rows": i, "table": "users"},
+				}
+				setBuffer(entry, buffer)
+				if err := CheckBufferOwnership(tt.formatter, entry); err != nil {
+					t.Fatalf("entry %d: %v", i, err)
+				}
+				buffers.Put(buffer)
+			}
+		})
+	}
+}
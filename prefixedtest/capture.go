@@ -0,0 +1,27 @@
+package prefixedtest
+
+import (
+	"bytes"
+
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+// Capture formats entries with a copy of f using a fixed clock, after
+// stamping them with Time, and returns the concatenated output.
+func Capture(f *prefixed.TextFormatter, entries ...*logrusEntry) ([]byte, error) {
+	f = f.Clone()
+	f.Clock = Clock{}
+	f.Since = since
+
+	var b bytes.Buffer
+	for _, entry := range entries {
+		stamped := *entry
+		stamped.Time = Time
+		out, err := f.Format(&stamped)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(out)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,24 @@
+//go:build !logrus_uppercase
+// +build !logrus_uppercase
+
+package prefixedtest
+
+import (
+	"bytes"
+
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	logrusEntry     = logrus.Entry
+	logrusFormatter = logrus.Formatter
+)
+
+func entryBuffer(entry *logrus.Entry) *bytes.Buffer {
+	return entry.Buffer
+}
+
+func setBuffer(entry *logrus.Entry, b *bytes.Buffer) bool {
+	entry.Buffer = b
+	return true
+}
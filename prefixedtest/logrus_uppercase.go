@@ -0,0 +1,25 @@
+//go:build logrus_uppercase
+// +build logrus_uppercase
+
+package prefixedtest
+
+import (
+	"bytes"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type (
+	logrusEntry     = logrus.Entry
+	logrusFormatter = logrus.Formatter
+)
+
+// This logrus version has no Entry.Buffer.
+
+func entryBuffer(*logrus.Entry) *bytes.Buffer {
+	return nil
+}
+
+func setBuffer(*logrus.Entry, *bytes.Buffer) bool {
+	return false
+}
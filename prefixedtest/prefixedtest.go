@@ -0,0 +1,75 @@
+// Package prefixedtest provides helpers for testing output produced by the
+// prefixed formatter against golden files.
+package prefixedtest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Time is the timestamp assigned to captured entries and returned by Clock,
+// so output does not depend on the wall clock.
+var Time = time.Date(2017, time.February, 8, 12, 0, 0, 0, time.UTC)
+
+// Elapsed is the time shown by relative timestamps in captured output.
+var Elapsed = 83 * time.Second
+
+// Clock is a prefixed.Clock always returning Time.
+type Clock struct{}
+
+func (Clock) Now() time.Time {
+	return Time
+}
+
+func since(time.Time) time.Duration {
+	return Elapsed
+}
+
+var update = flag.Bool("prefixedtest.update", false, "rewrite golden files with the current output")
+
+var ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// TB is the subset of testing.TB used by the helpers.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NormalizeANSI replaces ANSI escape sequences in b with readable
+// placeholders like <33m>, so colored output can be reviewed in golden files.
+func NormalizeANSI(b []byte) []byte {
+	return ansiRegexp.ReplaceAllFunc(b, func(seq []byte) []byte {
+		return append(append([]byte("<"), seq[2:]...), '>')
+	})
+}
+
+// AssertGolden compares got with the contents of testdata/<name>.golden.
+// Running the tests with -prefixedtest.update rewrites the file instead.
+func AssertGolden(t TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("prefixedtest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("prefixedtest: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("prefixedtest: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("prefixedtest: output does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
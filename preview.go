@@ -0,0 +1,47 @@
+package prefixed
+
+import "io"
+
+var previewEntries = []struct {
+	level   Level
+	prefix  string
+	message string
+	data    map[string]interface{}
+}{
+	{DebugLevel, "main", "Started observing beach", map[string]interface{}{"animal": "walrus", "number": 8}},
+	{InfoLevel, "sensor", "Temperature changes", map[string]interface{}{"temperature": -4}},
+	{WarnLevel, "main", "The group's number increased tremendously!", map[string]interface{}{"number": 122, "omg": true}},
+	{ErrorLevel, "sensor", "Lost connection", map[string]interface{}{"retries": 3}},
+	{FatalLevel, "main", "The ice breaks!", map[string]interface{}{"number": 100}},
+	{PanicLevel, "sensor", "It's over 9000!", map[string]interface{}{"animal": "orca", "size": 9009}},
+	{InfoLevel, "", "Entry without prefix", nil},
+}
+
+// PreviewScheme writes one colored sample entry per level, with prefixes and
+// fields, rendered with scheme, so theme authors can iterate on it without
+// writing a program.
+func PreviewScheme(w io.Writer, scheme *ColorScheme) error {
+	f := &TextFormatter{ForceColors: true, ShortTimestamp: true}
+	if err := f.SetColorScheme(scheme); err != nil {
+		return err
+	}
+
+	for _, sample := range previewEntries {
+		data := map[string]interface{}{}
+		for k, v := range sample.data {
+			data[k] = v
+		}
+		if sample.prefix != "" {
+			data["prefix"] = sample.prefix
+		}
+
+		b, err := f.format(&logEntry{time: f.now(), level: sample.level, message: sample.message, data: data})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
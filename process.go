@@ -0,0 +1,66 @@
+package prefixed
+
+import (
+	"io"
+	"sync"
+)
+
+// ProcessWriter renders the output of a subprocess line by line as entries
+// with a fixed prefix and level, so child process output matches the log
+// style of the parent. Close flushes a trailing line without newline.
+type ProcessWriter struct {
+	formatter *TextFormatter
+	out       io.Writer
+	prefix    string
+	level     Level
+	mu        *sync.Mutex
+	lines     lineBuffer
+}
+
+// NewProcessWriter returns a writer rendering each line written to it as an
+// entry with the given prefix and level.
+func NewProcessWriter(w io.Writer, f *TextFormatter, prefix string, level Level) *ProcessWriter {
+	return &ProcessWriter{formatter: f, out: w, prefix: prefix, level: level, mu: &sync.Mutex{}}
+}
+
+// NewProcessWriters returns writers suitable for the Stdout and Stderr of an
+// exec.Cmd. Stdout lines are rendered at Info level and stderr lines at Warn
+// level. Both writers serialize their output to w.
+func NewProcessWriters(w io.Writer, f *TextFormatter, prefix string) (stdout, stderr *ProcessWriter) {
+	stdout = NewProcessWriter(w, f, prefix, InfoLevel)
+	stderr = NewProcessWriter(w, f, prefix, WarnLevel)
+	stderr.mu = stdout.mu
+	return stdout, stderr
+}
+
+func (w *ProcessWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(p), w.lines.write(p, w.emit)
+}
+
+func (w *ProcessWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines.flush(w.emit)
+}
+
+func (w *ProcessWriter) emit(line string) error {
+	entry := &logEntry{
+		time:    w.formatter.now(),
+		level:   w.level,
+		message: line,
+		data:    map[string]interface{}{},
+		out:     w.out,
+	}
+	if w.prefix != "" {
+		entry.data["prefix"] = w.prefix
+	}
+
+	b, err := w.formatter.format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(b)
+	return err
+}
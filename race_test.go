@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package prefixed
+
+// The race detector makes sync.Pool drop items at random, so allocation
+// counts aren't meaningful.
+const raceEnabled = true
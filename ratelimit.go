@@ -0,0 +1,59 @@
+package prefixed
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// dumpLimiter is a token bucket limiting heavy extras such as crash
+// summaries and stack dumps.
+type dumpLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// allowDump reports whether a heavy extra may be rendered now, consuming a
+// token if so. Without DumpRate, dumps are not limited.
+func (f *TextFormatter) allowDump() bool {
+	if f.DumpRate <= 0 {
+		return true
+	}
+	burst := float64(f.DumpBurst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l := &f.dumpLimiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := f.now()
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * f.DumpRate
+		if l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// appendDumpSuppressed notes that a heavy extra was left out by the limiter.
+func (f *TextFormatter) appendDumpSuppressed(b *bytes.Buffer, isColored bool) {
+	const note = "(dump suppressed)"
+	if isColored {
+		b.WriteString(f.compiledColorScheme().TimestampColor(note))
+	} else {
+		b.WriteString(note)
+	}
+	f.appendLineEnding(b)
+}
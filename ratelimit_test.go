@@ -0,0 +1,42 @@
+package prefixed
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDumpRateIgnoresPlainErrors(t *testing.T) {
+	f := &TextFormatter{
+		ForceColors:      true,
+		DisableTimestamp: true,
+		ErrorDetails:     true,
+		DumpRate:         0.001,
+		DumpBurst:        1,
+	}
+	format := func(err error) string {
+		out, err := f.Format(&logrusEntry{
+			Level:   logrusLevel(ErrorLevel),
+			Message: "request failed",
+			Data:    map[string]interface{}{"error": err},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	for i := 0; i < 3; i++ {
+		if out := format(errors.New("boom")); strings.Contains(out, "dump suppressed") {
+			t.Fatalf("plain error %d: unexpected suppression in %q", i, out)
+		}
+	}
+	wrapped := fmt.Errorf("query: %w", errors.New("timeout"))
+	if out := format(wrapped); !strings.Contains(out, "caused by: timeout") {
+		t.Errorf("first wrapped error: missing details in %q", out)
+	}
+	if out := format(wrapped); !strings.Contains(out, "dump suppressed") {
+		t.Errorf("second wrapped error: expected suppression in %q", out)
+	}
+}
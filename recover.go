@@ -0,0 +1,37 @@
+package prefixed
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// format renders entry. Unless DisablePanicRecovery is set, panics while
+// formatting are recovered from, reported to OnError and replaced with a
+// minimal rendering, so logging never crashes the program.
+func (f *TextFormatter) format(entry *logEntry) (b []byte, err error) {
+	if f.DisablePanicRecovery {
+		return f.formatEntry(entry)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			b, err = f.fallbackFormat(entry), nil
+			if f.OnError != nil {
+				f.OnError(fmt.Errorf("prefixed: recovered from panic while formatting entry: %v", r))
+			}
+		}
+	}()
+	return f.formatEntry(entry)
+}
+
+// fallbackFormat renders entry as "time level msg", relying on as little of
+// the formatter as possible.
+func (f *TextFormatter) fallbackFormat(entry *logEntry) []byte {
+	b := entry.buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	b.Reset()
+	fmt.Fprintf(b, "%s %s %s\n", entry.time.Format(time.RFC3339), entry.level, entry.message)
+	return b.Bytes()
+}
@@ -0,0 +1,78 @@
+package prefixed
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Redactor replaces sensitive field values before they are rendered. Redact
+// returns the value to render and whether it differs from value.
+type Redactor interface {
+	Redact(key string, value interface{}) (interface{}, bool)
+}
+
+// PatternRedactor redacts fields by key and value patterns.
+type PatternRedactor struct {
+	// Key patterns in path.Match syntax, matched case-insensitively, e.g.
+	// "password", "*_token" or "authorization". Values of matching fields
+	// are replaced with Redacted as a whole.
+	Keys []string
+
+	// Parts of string values matching these expressions are replaced with
+	// Redacted, e.g. `Bearer \S+`.
+	Values []*regexp.Regexp
+}
+
+// NewPatternRedactor returns a PatternRedactor for keys and value
+// expressions. It panics if an expression does not compile.
+func NewPatternRedactor(keys []string, values ...string) *PatternRedactor {
+	r := &PatternRedactor{Keys: keys}
+	for _, value := range values {
+		r.Values = append(r.Values, regexp.MustCompile(value))
+	}
+	return r
+}
+
+func (r *PatternRedactor) Redact(key string, value interface{}) (interface{}, bool) {
+	lowerKey := strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if matched, _ := path.Match(strings.ToLower(pattern), lowerKey); matched {
+			return Redacted, true
+		}
+	}
+	if len(r.Values) == 0 {
+		return value, false
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return value, false
+	}
+	redacted := s
+	for _, re := range r.Values {
+		redacted = re.ReplaceAllLiteralString(redacted, string(Redacted))
+	}
+	if redacted == s {
+		return value, false
+	}
+	return redacted, true
+}
+
+// redact applies the Redactors to the value of the field key.
+func (f *TextFormatter) redact(key string, value interface{}) interface{} {
+	for _, r := range f.Redactors {
+		if redacted, ok := r.Redact(key, value); ok {
+			value = redacted
+		}
+	}
+	return value
+}
@@ -0,0 +1,100 @@
+package prefixed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Reformat reads JSON log lines, such as those written by logrus's
+// JSONFormatter, from r and writes them to w rendered by the formatter. The
+// reserved time, level, msg and prefix keys are recognized using FieldMap.
+// Lines that are not JSON objects are copied unchanged.
+func (f *TextFormatter) Reformat(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		entry, ok := f.parseJSONEntry(line)
+		if !ok {
+			if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		entry.out = w
+		b, err := f.format(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (f *TextFormatter) parseJSONEntry(line []byte) (*logEntry, bool) {
+	data := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil || data == nil {
+		// A JSON null decodes without error, but leaves no object.
+		return nil, false
+	}
+
+	entry := &logEntry{
+		time:  f.now(),
+		level: InfoLevel,
+		data:  data,
+	}
+
+	timeKey := f.FieldMap.resolve(FieldKeyTime)
+	if t, ok := parseJSONTime(data[timeKey]); ok {
+		entry.time = t
+		delete(data, timeKey)
+	}
+
+	levelKey := f.FieldMap.resolve(FieldKeyLevel)
+	if name, ok := data[levelKey].(string); ok {
+		if level, ok := parseLevel(name); ok {
+			entry.level = level
+			delete(data, levelKey)
+		}
+	}
+
+	msgKey := f.FieldMap.resolve(FieldKeyMsg)
+	if msg, ok := data[msgKey].(string); ok {
+		entry.message = msg
+		delete(data, msgKey)
+	}
+
+	prefixKey := f.FieldMap.resolve(FieldKeyPrefix)
+	if prefix, ok := data[prefixKey]; ok && prefixKey != "prefix" {
+		delete(data, prefixKey)
+		data["prefix"] = prefix
+	}
+	return entry, true
+}
+
+// parseJSONTime accepts RFC 3339 strings and numeric Unix timestamps in
+// seconds.
+func parseJSONTime(value interface{}) (time.Time, bool) {
+	switch value := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return t, err == nil
+	case json.Number:
+		seconds, err := value.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(0, int64(seconds*float64(time.Second))), true
+	}
+	return time.Time{}, false
+}
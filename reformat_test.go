@@ -0,0 +1,51 @@
+package prefixed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReformat(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"warning","msg":"disk almost full","prefix":"fs","time":"2026-10-16T01:02:03Z","free_pct":4}`,
+		`plain text line`,
+		`{"level":"error","msg":"request failed","status":503,"time":1792112523}`,
+		`null`,
+		``,
+		`[1, 2, 3]`,
+		`{"msg":"no level"}`,
+		`{"broken":`,
+		``,
+	}, "\n")
+	want := strings.Join([]string{
+		`[01:02:03] WARNING fs: disk almost full free_pct=4`,
+		`plain text line`,
+		`[01:02:03]   ERROR request failed status=503`,
+		`null`,
+		``,
+		`[1, 2, 3]`,
+		`[09:00:00]    INFO no level`,
+		`{"broken":`,
+		``,
+	}, "\n")
+
+	f := &TextFormatter{
+		ForceColors:     true,
+		TimestampFormat: "15:04:05",
+		TimestampUTC:    true,
+		Clock:           ClockFunc(func() time.Time { return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC) }),
+	}
+	if err := f.SetColorScheme(offStyles); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := f.Reformat(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	// The colored layout with colors off keeps only resets.
+	if got := stripANSIString(out.String()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package prefixed
+
+// SchemeBuilder builds a ColorScheme step by step, starting from the default
+// scheme:
+//
+//	scheme, err := prefixed.NewScheme().Info("green").Warn("yellow+b").Prefix("cyan").Build()
+//
+// Styles are validated as they are set and Build reports all invalid ones.
+type SchemeBuilder struct {
+	scheme  ColorScheme
+	invalid []string
+}
+
+// NewScheme returns a builder initialized with the default styles.
+func NewScheme() *SchemeBuilder {
+	b := &SchemeBuilder{scheme: *defaultColorScheme}
+	b.scheme.Name = ""
+	return b
+}
+
+func (b *SchemeBuilder) set(field *string, name, style string) *SchemeBuilder {
+	if !validStyle(style) {
+		b.invalid = append(b.invalid, invalidStyle(name, style))
+		return b
+	}
+	*field = style
+	return b
+}
+
+// Name sets the name of the scheme.
+func (b *SchemeBuilder) Name(name string) *SchemeBuilder {
+	b.scheme.Name = name
+	return b
+}
+
+func (b *SchemeBuilder) Info(style string) *SchemeBuilder {
+	return b.set(&b.scheme.InfoLevelStyle, "InfoLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Warn(style string) *SchemeBuilder {
+	return b.set(&b.scheme.WarnLevelStyle, "WarnLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Error(style string) *SchemeBuilder {
+	return b.set(&b.scheme.ErrorLevelStyle, "ErrorLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Fatal(style string) *SchemeBuilder {
+	return b.set(&b.scheme.FatalLevelStyle, "FatalLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Panic(style string) *SchemeBuilder {
+	return b.set(&b.scheme.PanicLevelStyle, "PanicLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Debug(style string) *SchemeBuilder {
+	return b.set(&b.scheme.DebugLevelStyle, "DebugLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Trace(style string) *SchemeBuilder {
+	return b.set(&b.scheme.TraceLevelStyle, "TraceLevelStyle", style)
+}
+
+func (b *SchemeBuilder) Prefix(style string) *SchemeBuilder {
+	return b.set(&b.scheme.PrefixStyle, "PrefixStyle", style)
+}
+
+func (b *SchemeBuilder) Timestamp(style string) *SchemeBuilder {
+	return b.set(&b.scheme.TimestampStyle, "TimestampStyle", style)
+}
+
+func (b *SchemeBuilder) Caller(style string) *SchemeBuilder {
+	return b.set(&b.scheme.CallerStyle, "CallerStyle", style)
+}
+
+func (b *SchemeBuilder) ErrorDetail(style string) *SchemeBuilder {
+	return b.set(&b.scheme.ErrorDetailStyle, "ErrorDetailStyle", style)
+}
+
+func (b *SchemeBuilder) FieldKey(style string) *SchemeBuilder {
+	return b.set(&b.scheme.FieldKeyStyle, "FieldKeyStyle", style)
+}
+
+func (b *SchemeBuilder) FieldValue(style string) *SchemeBuilder {
+	return b.set(&b.scheme.FieldValueStyle, "FieldValueStyle", style)
+}
+
+// Message sets the style of messages of level.
+func (b *SchemeBuilder) Message(level Level, style string) *SchemeBuilder {
+	switch level {
+	case InfoLevel:
+		return b.set(&b.scheme.InfoMessageStyle, "InfoMessageStyle", style)
+	case WarnLevel:
+		return b.set(&b.scheme.WarnMessageStyle, "WarnMessageStyle", style)
+	case ErrorLevel:
+		return b.set(&b.scheme.ErrorMessageStyle, "ErrorMessageStyle", style)
+	case FatalLevel:
+		return b.set(&b.scheme.FatalMessageStyle, "FatalMessageStyle", style)
+	case PanicLevel:
+		return b.set(&b.scheme.PanicMessageStyle, "PanicMessageStyle", style)
+	case TraceLevel:
+		return b.set(&b.scheme.TraceMessageStyle, "TraceMessageStyle", style)
+	default:
+		return b.set(&b.scheme.DebugMessageStyle, "DebugMessageStyle", style)
+	}
+}
+
+// Build returns the resulting scheme, or an error listing every invalid
+// style passed to the builder.
+func (b *SchemeBuilder) Build() (*ColorScheme, error) {
+	if err := invalidStylesError(b.invalid); err != nil {
+		return nil, err
+	}
+	scheme := b.scheme
+	return &scheme, nil
+}
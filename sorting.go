@@ -0,0 +1,143 @@
+package prefixed
+
+import (
+	"sort"
+	"sync"
+)
+
+// fieldSorter orders fields by key, or by descending weight, then key, when
+// weights are set. It is pooled, so sorting doesn't allocate per entry.
+type fieldSorter struct {
+	fields  []Field
+	weights map[string]int
+}
+
+func (s *fieldSorter) Len() int      { return len(s.fields) }
+func (s *fieldSorter) Swap(i, j int) { s.fields[i], s.fields[j] = s.fields[j], s.fields[i] }
+func (s *fieldSorter) Less(i, j int) bool {
+	if s.weights != nil {
+		wi, wj := s.weights[s.fields[i].Key], s.weights[s.fields[j].Key]
+		if wi != wj {
+			return wi > wj
+		}
+	}
+	return s.fields[i].Key < s.fields[j].Key
+}
+
+var fieldSorterPool = sync.Pool{
+	New: func() interface{} { return new(fieldSorter) },
+}
+
+func sortFieldsBy(fields []Field, weights map[string]int) {
+	s := fieldSorterPool.Get().(*fieldSorter)
+	s.fields, s.weights = fields, weights
+	sort.Sort(s)
+	s.fields, s.weights = nil, nil
+	fieldSorterPool.Put(s)
+}
+
+// keyOrder is the pooled scratch space of sortFieldsWith. As a sort.Interface
+// it orders the indexes of fields by key, then by index.
+type keyOrder struct {
+	fields []Field
+	keys   []string
+	order  []int
+	taken  []int
+	sorted []Field
+}
+
+func (s *keyOrder) Len() int      { return len(s.order) }
+func (s *keyOrder) Swap(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] }
+func (s *keyOrder) Less(i, j int) bool {
+	ki, kj := s.fields[s.order[i]].Key, s.fields[s.order[j]].Key
+	if ki != kj {
+		return ki < kj
+	}
+	return s.order[i] < s.order[j]
+}
+
+var keyOrderPool = sync.Pool{
+	New: func() interface{} { return new(keyOrder) },
+}
+
+func getKeyOrder(fields []Field) *keyOrder {
+	s := keyOrderPool.Get().(*keyOrder)
+	n := len(fields)
+	if cap(s.order) < n {
+		s.keys, s.order, s.taken, s.sorted = make([]string, n), make([]int, n), make([]int, n), make([]Field, n)
+	}
+	s.fields = fields
+	s.keys, s.order, s.taken, s.sorted = s.keys[:n], s.order[:n], s.taken[:n], s.sorted[:n]
+	for i, field := range fields {
+		s.keys[i], s.order[i], s.taken[i] = field.Key, i, 0
+	}
+	return s
+}
+
+func putKeyOrder(s *keyOrder) {
+	// Don't retain keys and values of formatted entries.
+	for i := range s.keys {
+		s.keys[i], s.sorted[i] = "", Field{}
+	}
+	s.fields = nil
+	keyOrderPool.Put(s)
+}
+
+// sortFieldsWith reorders fields according to the order sortKeys puts their
+// keys in. Fields sharing a key keep their relative order. If sortKeys
+// doesn't return a permutation of the keys, fields are left as they are.
+func sortFieldsWith(fields []Field, sortKeys func([]string)) {
+	s := getKeyOrder(fields)
+	defer putKeyOrder(s)
+
+	sortKeys(s.keys)
+	sort.Sort(s)
+	for i, key := range s.keys {
+		// Fields with key are a run in order; taken counts those placed.
+		first := sort.Search(len(s.order), func(j int) bool { return fields[s.order[j]].Key >= key })
+		if first == len(s.order) {
+			return
+		}
+		j := first + s.taken[first]
+		if j >= len(s.order) || fields[s.order[j]].Key != key {
+			return
+		}
+		s.taken[first]++
+		s.sorted[i] = fields[s.order[j]]
+	}
+	copy(fields, s.sorted)
+}
+
+func (f *TextFormatter) sortFields(fields []Field) {
+	switch {
+	case f.DisableSorting:
+	case f.SortingFunc != nil:
+		sortFieldsWith(fields, f.SortingFunc)
+	case len(f.FieldWeights) > 0:
+		sortFieldsBy(fields, f.FieldWeights)
+	default:
+		sortFieldsBy(fields, nil)
+	}
+	if len(f.FieldOrder) > 0 {
+		orderFields(fields, f.FieldOrder)
+	}
+}
+
+// orderFields moves the fields listed in order to the front, in that order,
+// keeping the relative order of the others.
+func orderFields(fields []Field, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, key := range order {
+		if _, ok := rank[key]; !ok {
+			rank[key] = i
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, iListed := rank[fields[i].Key]
+		rj, jListed := rank[fields[j].Key]
+		if iListed && jListed {
+			return ri < rj
+		}
+		return iListed && !jListed
+	})
+}
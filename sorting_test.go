@@ -0,0 +1,62 @@
+package prefixed
+
+import (
+	"reflect"
+	"testing"
+)
+
+// insertionSort is an allocation-free SortingFunc.
+func insertionSort(keys []string) {
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
+
+func fieldKeysAndValues(fields []Field) []interface{} {
+	var kv []interface{}
+	for _, field := range fields {
+		kv = append(kv, field.Key, field.Value)
+	}
+	return kv
+}
+
+func TestSortFieldsWith(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		sortKeys func([]string)
+		want     []interface{}
+	}{
+		{"sorted", insertionSort, []interface{}{"a", 2, "b", 1, "b", 3, "c", 4}},
+		{"not a permutation", func(keys []string) { keys[0] = "x" }, []interface{}{"b", 1, "a", 2, "b", 3, "c", 4}},
+		{"duplicated key", func(keys []string) { keys[1] = keys[0] }, []interface{}{"b", 1, "a", 2, "b", 3, "c", 4}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := []Field{{Key: "b", Value: 1}, {Key: "a", Value: 2}, {Key: "b", Value: 3}, {Key: "c", Value: 4}}
+			sortFieldsWith(fields, tt.sortKeys)
+			if got := fieldKeysAndValues(fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortFieldsAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts vary with the race detector")
+	}
+	fields := []Field{{Key: "z"}, {Key: "b"}, {Key: "m"}, {Key: "a"}, {Key: "q"}, {Key: "c"}}
+	for _, tt := range []struct {
+		name string
+		f    *TextFormatter
+	}{
+		{"by key", &TextFormatter{}},
+		{"by weight", &TextFormatter{FieldWeights: map[string]int{"q": 1}}},
+		{"sorting func", &TextFormatter{SortingFunc: insertionSort}},
+	} {
+		if allocs := testing.AllocsPerRun(100, func() { tt.f.sortFields(fields) }); allocs != 0 {
+			t.Errorf("%s: %v allocations per sort, want 0", tt.name, allocs)
+		}
+	}
+}
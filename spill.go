@@ -0,0 +1,42 @@
+package prefixed
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Width the spilled field block is laid out for when MaxLineLength is not set.
+const spillWidth = 120
+
+// appendSpilledFields renders the fields beyond InlineFieldLimit as an
+// indented block of aligned columns below the entry.
+func (f *TextFormatter) appendSpilledFields(b *bytes.Buffer, texts []string) {
+	widths := make([]int, len(texts))
+	column := 0
+	for i, text := range texts {
+		widths[i] = visibleWidth(text)
+		if widths[i] > column {
+			column = widths[i]
+		}
+	}
+	column += 2
+
+	width := spillWidth
+	if f.MaxLineLength > 0 {
+		width = f.MaxLineLength
+	}
+	columns := (width - len(indent(0))) / column
+	if columns < 1 {
+		columns = 1
+	}
+
+	for i, text := range texts {
+		if i%columns == 0 {
+			b.WriteString(f.lineEnding())
+			b.WriteString(indent(0))
+		} else {
+			b.WriteString(strings.Repeat(" ", column-widths[i-1]))
+		}
+		b.WriteString(text)
+	}
+}
@@ -0,0 +1,55 @@
+package prefixed
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// LevelSplitter is a logrus hook routing Warn and more severe entries to one
+// writer and all other entries to another, as CLIs are expected to keep
+// diagnostics off stdout. Each destination gets its own copy of the
+// formatter, so colors are decided by the destination's TTY-ness. Install it
+// with logger.Hooks.Add and discard the logger's own output:
+//
+//	logger.Hooks.Add(prefixed.NewStdLevelSplitter(formatter))
+//	logger.Out = ioutil.Discard
+type LevelSplitter struct {
+	out, err                   io.Writer
+	outFormatter, errFormatter *TextFormatter
+	mu                         sync.Mutex
+}
+
+// NewLevelSplitter returns a splitter writing entries formatted by copies of f
+// to out, or to err for Warn and more severe entries.
+func NewLevelSplitter(f *TextFormatter, out, err io.Writer) *LevelSplitter {
+	return &LevelSplitter{
+		out:          out,
+		err:          err,
+		outFormatter: f.Clone(),
+		errFormatter: f.Clone(),
+	}
+}
+
+// NewStdLevelSplitter returns a splitter writing to os.Stdout and os.Stderr.
+func NewStdLevelSplitter(f *TextFormatter) *LevelSplitter {
+	return NewLevelSplitter(f, os.Stdout, os.Stderr)
+}
+
+func (s *LevelSplitter) write(entry *logEntry) error {
+	w, f := s.out, s.outFormatter
+	if entry.level <= WarnLevel {
+		w, f = s.err, s.errFormatter
+	}
+	entry.out = w
+
+	b, err := f.format(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,155 @@
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// logFlagsHeader matches the header the standard library log package puts
+// before messages for its Ldate, Ltime, Lmicroseconds, Lshortfile and
+// Llongfile flags, e.g. "2009/01/23 01:23:23.123123 d.go:23: ".
+var logFlagsHeader = regexp.MustCompile(`^(?:\d{4}/\d{2}/\d{2} )?(?:\d{2}:\d{2}:\d{2}(?:\.\d{6})? )?(?:\S+\.go:\d+: )?`)
+
+// levelMarkers are the message prefixes recognized by LogWriter to infer the
+// level of a line.
+var levelMarkers = []struct {
+	marker string
+	level  Level
+}{
+	{"PANIC:", PanicLevel},
+	{"FATAL:", FatalLevel},
+	{"ERROR:", ErrorLevel},
+	{"ERR:", ErrorLevel},
+	{"WARNING:", WarnLevel},
+	{"WARN:", WarnLevel},
+	{"INFO:", InfoLevel},
+	{"DEBUG:", DebugLevel},
+	{"TRACE:", TraceLevel},
+}
+
+// LogWriter is an io.Writer accepting lines written by the standard library
+// log package and re-emitting them through the formatter. Use it as the
+// output of a *log.Logger; the date, time and file headers added by its flags
+// are dropped. A leading [prefix] is extracted as usual and markers like
+// "ERROR:" or "WARN:" before or after it select the level; unmarked lines use
+// the default level. Loggers with a prefix of their own are set up with
+// Attach, so the prefix is told apart from the headers.
+type LogWriter struct {
+	formatter *TextFormatter
+	out       io.Writer
+	level     Level
+	mu        sync.Mutex
+	lines     lineBuffer
+
+	// Prefix of the attached logger and whether it follows the headers
+	// (log.Lmsgprefix)
+	logPrefix string
+	msgPrefix bool
+}
+
+// NewLogWriter returns a writer formatting lines with f at level unless a
+// level marker is present, and writing them to w.
+func NewLogWriter(w io.Writer, f *TextFormatter, level Level) *LogWriter {
+	return &LogWriter{formatter: f, out: w, level: level}
+}
+
+// Attach makes w the output of logger and records its prefix and flags, which
+// must not change afterwards. The prefix is kept at the start of messages.
+func (w *LogWriter) Attach(logger *log.Logger) {
+	w.mu.Lock()
+	w.logPrefix = logger.Prefix()
+	w.msgPrefix = logger.Flags()&log.Lmsgprefix != 0
+	w.mu.Unlock()
+	logger.SetOutput(w)
+}
+
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(p), w.lines.write(p, w.emit)
+}
+
+func (w *LogWriter) emit(line string) error {
+	if !w.msgPrefix {
+		line = strings.TrimPrefix(line, w.logPrefix)
+	}
+	line = strings.TrimPrefix(line, logFlagsHeader.FindString(line))
+	if w.msgPrefix {
+		line = strings.TrimPrefix(line, w.logPrefix)
+	}
+	level, message, ok := parseLevelMarker(line)
+	if !ok {
+		// The marker may follow the prefix, which stays in the message.
+		message = strings.TrimSpace(message)
+		if prefix, rest := w.formatter.extractPrefix(message); prefix != "" {
+			section := message[:len(message)-len(rest)]
+			if level, rest, ok = parseLevelMarker(rest); ok {
+				message = section + rest
+			}
+		}
+	}
+	if !ok {
+		level = w.level
+	}
+	if w.logPrefix != "" {
+		message = w.logPrefix + message
+	}
+
+	entry := &logEntry{
+		time:    w.formatter.now(),
+		level:   level,
+		message: message,
+		data:    map[string]interface{}{},
+		out:     w.out,
+	}
+
+	b, err := w.formatter.format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(b)
+	return err
+}
+
+// parseLevelMarker strips a leading level marker from msg.
+func parseLevelMarker(msg string) (Level, string, bool) {
+	for _, m := range levelMarkers {
+		if strings.HasPrefix(msg, m.marker) {
+			return m.level, strings.TrimSpace(msg[len(m.marker):]), true
+		}
+	}
+	return 0, msg, false
+}
+
+// lineBuffer accumulates written bytes and hands out complete lines.
+type lineBuffer struct {
+	buf bytes.Buffer
+}
+
+func (l *lineBuffer) write(p []byte, emit func(line string) error) error {
+	l.buf.Write(p)
+	for {
+		i := bytes.IndexByte(l.buf.Bytes(), '\n')
+		if i < 0 {
+			return nil
+		}
+		line := string(l.buf.Next(i + 1))
+		if err := emit(strings.TrimRight(line, "\r\n")); err != nil {
+			return err
+		}
+	}
+}
+
+// flush hands out the remaining incomplete line, if any.
+func (l *lineBuffer) flush(emit func(line string) error) error {
+	if l.buf.Len() == 0 {
+		return nil
+	}
+	line := l.buf.String()
+	l.buf.Reset()
+	return emit(strings.TrimRight(line, "\r\n"))
+}
@@ -0,0 +1,35 @@
+package prefixed
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestLogWriterLoggerPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		flags  int
+		print  string
+		want   string
+	}{
+		{"no prefix", "", log.LstdFlags, "ERROR: boom", `level=error msg=boom`},
+		{"prefix", "myapp: ", log.LstdFlags, "ERROR: boom", `level=error msg="myapp: boom"`},
+		{"prefix and file", "myapp: ", log.LstdFlags | log.Lmicroseconds | log.Lshortfile, "WARN: slow", `level=warning msg="myapp: slow"`},
+		{"msgprefix", "myapp: ", log.LstdFlags | log.Lmsgprefix, "ERROR: boom", `level=error msg="myapp: boom"`},
+		{"bracket prefix", "[db] ", log.LstdFlags | log.Lmsgprefix, "WARN: slow", `level=warning msg="[db] slow"`},
+		{"unmarked", "myapp: ", log.LstdFlags, "started", `level=info msg="myapp: started"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewLogWriter(&b, &TextFormatter{DisableColors: true, DisableTimestamp: true}, InfoLevel)
+			logger := log.New(nil, tt.prefix, tt.flags)
+			w.Attach(logger)
+			logger.Print(tt.print)
+			if got, want := b.String(), tt.want+" \n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package prefixed
+
+import (
+	"fmt"
+	"time"
+)
+
+var timestampLayoutNames = map[string]string{
+	time.ANSIC:       "ANSIC",
+	time.UnixDate:    "UnixDate",
+	time.RubyDate:    "RubyDate",
+	time.RFC822:      "RFC822",
+	time.RFC822Z:     "RFC822Z",
+	time.RFC850:      "RFC850",
+	time.RFC1123:     "RFC1123",
+	time.RFC1123Z:    "RFC1123Z",
+	time.RFC3339:     "RFC3339",
+	time.RFC3339Nano: "RFC3339Nano",
+	time.Kitchen:     "Kitchen",
+	time.Stamp:       "Stamp",
+	time.StampMilli:  "StampMilli",
+	time.StampMicro:  "StampMicro",
+	time.StampNano:   "StampNano",
+}
+
+// String describes the active options of the formatter for startup
+// diagnostics, e.g. "prefixed text, colors=on, ts=RFC3339, theme=default".
+func (f *TextFormatter) String() string {
+	c := f.Config()
+
+	format := "text"
+	if c.Encoder != "colored" && c.Encoder != "logfmt" {
+		format = c.Encoder
+	}
+
+	colors := "off"
+	if c.Colors {
+		colors = "on"
+	}
+
+	ts := c.Timestamp
+	if ts == "full" {
+		ts = c.TimestampFormat
+		if name, ok := timestampLayoutNames[ts]; ok {
+			ts = name
+		}
+	}
+
+	return fmt.Sprintf("prefixed %s, colors=%s, ts=%s, theme=%s", format, colors, ts, c.ColorScheme.Name)
+}
@@ -0,0 +1,57 @@
+package prefixed
+
+// Styler exposes the color functions of a formatter's active color scheme,
+// so adjacent output such as progress bars or prompts can match the log
+// theme. The functions always apply colors; whether to use them is up to
+// the caller.
+type Styler struct {
+	f      *TextFormatter
+	colors *compiledColorScheme
+}
+
+// Styler returns the color functions of the current color scheme. Later
+// calls to SetColorScheme don't affect the returned Styler.
+func (f *TextFormatter) Styler() *Styler {
+	return &Styler{f: f, colors: f.compiledColorScheme()}
+}
+
+// Level returns the color function of level.
+func (s *Styler) Level(level Level) func(string) string {
+	return s.colors.levelColor(level)
+}
+
+// Prefix returns the color function of prefix, honoring PrefixColors and
+// AutoPrefixColors.
+func (s *Styler) Prefix(prefix string) func(string) string {
+	return s.f.prefixColor(prefix, s.colors)
+}
+
+// Timestamp returns the color function of timestamps.
+func (s *Styler) Timestamp() func(string) string {
+	return s.colors.TimestampColor
+}
+
+// Caller returns the color function of callers.
+func (s *Styler) Caller() func(string) string {
+	return s.colors.CallerColor
+}
+
+// FieldKey returns the color function of field keys of entries of level.
+func (s *Styler) FieldKey(level Level) func(string) string {
+	return s.colors.fieldKeyColor(level)
+}
+
+// FieldValue returns the color function of field values.
+func (s *Styler) FieldValue() func(string) string {
+	return s.colors.FieldValueColor
+}
+
+// Message returns the color function of messages of level.
+func (s *Styler) Message(level Level) func(string) string {
+	return s.colors.messageColor(level)
+}
+
+// ErrorDetail returns the color function of error chains and stack traces.
+func (s *Styler) ErrorDetail() func(string) string {
+	return s.colors.ErrorDetailColor
+}
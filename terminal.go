@@ -0,0 +1,66 @@
+package prefixed
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"sync/atomic"
+)
+
+// terminal caches the capabilities of a destination writer.
+type terminal struct {
+	out        io.Writer
+	isTerminal bool
+
+	// Width as of the resizes-th terminal resize
+	width   int64
+	resizes uint64
+}
+
+// terminal returns the capabilities of out. Files are detected on first
+// use and cached; other writers, e.g. per-request buffers, are cheap to
+// detect and not cached, so they don't accumulate, unless overridden with
+// SetTerminal.
+func (f *TextFormatter) terminal(out io.Writer) *terminal {
+	if out == nil || reflect.TypeOf(out).Comparable() {
+		if t, ok := f.terminals.Load(out); ok {
+			return t.(*terminal)
+		}
+	}
+
+	if _, ok := out.(*os.File); !ok {
+		return f.detectTerminal(out, out != nil && isTerminal(out))
+	}
+	t, _ := f.terminals.LoadOrStore(out, f.detectTerminal(out, isTerminal(out)))
+	return t.(*terminal)
+}
+
+func (f *TextFormatter) detectTerminal(out io.Writer, isTerminal bool) *terminal {
+	t := &terminal{out: out, isTerminal: isTerminal}
+	if f.WrapLines || f.SpacePaddingAuto {
+		t.width = int64(terminalWidth(out))
+		t.resizes = watchResizes()
+	}
+	return t
+}
+
+// SetTerminal overrides whether out is a terminal, e.g. for a pseudo-terminal
+// wrapper or a pipe to a pager rendering colors. DisableColors, ForceColors
+// and the color environment variables still take precedence. out must be
+// comparable, as pointers are.
+func (f *TextFormatter) SetTerminal(out io.Writer, isTerminal bool) {
+	f.terminals.Store(out, f.detectTerminal(out, isTerminal))
+}
+
+// currentWidth returns the width of the terminal, updated after it was
+// resized. Without a terminal, it is the fallback of terminalWidth.
+func (t *terminal) currentWidth() int {
+	if t == nil {
+		return terminalWidth(nil)
+	}
+	if resizes := terminalResizes(); resizes != atomic.LoadUint64(&t.resizes) {
+		atomic.StoreUint64(&t.resizes, resizes)
+		atomic.StoreInt64(&t.width, int64(terminalWidth(t.out)))
+	}
+	return int(atomic.LoadInt64(&t.width))
+}
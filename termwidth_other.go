@@ -0,0 +1,22 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package prefixed
+
+import "io"
+
+// watchResizes returns zero: resizes are only detected on Unix systems.
+func watchResizes() uint64 {
+	return 0
+}
+
+// terminalResizes returns zero: resizes are only detected on Unix systems.
+func terminalResizes() uint64 {
+	return 0
+}
+
+// ttyWidth returns zero: the terminal width is only detected on Unix
+// systems.
+func ttyWidth(w io.Writer) int {
+	return 0
+}
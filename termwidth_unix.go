@@ -0,0 +1,57 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package prefixed
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	resizes     uint64
+	resizesOnce sync.Once
+)
+
+// watchResizes starts counting SIGWINCH signals, once per process, and
+// returns the number of resizes so far.
+func watchResizes() uint64 {
+	resizesOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGWINCH)
+		go func() {
+			for range signals {
+				atomic.AddUint64(&resizes, 1)
+			}
+		}()
+	})
+	return terminalResizes()
+}
+
+// terminalResizes returns the number of times the terminal was resized since
+// watchResizes was first called.
+func terminalResizes() uint64 {
+	return atomic.LoadUint64(&resizes)
+}
+
+// ttyWidth returns the number of columns of the terminal w writes to, or
+// zero if unknown.
+func ttyWidth(w io.Writer) int {
+	file, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0
+	}
+	return int(size.cols)
+}
@@ -0,0 +1,237 @@
+package prefixed
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// coloredEncoder renders the bracketed, colored layout used on terminals.
+type coloredEncoder struct {
+	f *TextFormatter
+}
+
+func (e *coloredEncoder) Encode(b *bytes.Buffer, r *Record) error {
+	f := e.f
+	colors := f.compiledColorScheme()
+	levelColor := colors.levelColor(r.Level)
+	layout, err := f.layout()
+	if err != nil {
+		return err
+	}
+
+	s := &layoutSections{
+		Level:   levelColor(f.levelLabel(r.Level)),
+		Message: r.Message,
+	}
+	if r.Prefix != "" {
+		s.Prefix = f.renderPrefix(r.Prefix, colors)
+	}
+	s.Prefix = f.padPrefix(s.Prefix, !r.measuring)
+	if r.Message == "" && f.EmptyMessage == EmptyMessageQuoted {
+		s.Message = `""`
+	}
+	if f.SpacePadding != 0 && !f.SpacePaddingAuto && s.Message != "" {
+		// Padding counts visible columns, so colored messages and multi-byte
+		// characters line up like plain ones.
+		s.Message = padRight(s.Message, f.SpacePadding)
+	}
+	switch {
+	case f.CompactMode && r.Level > WarnLevel:
+		// Routine entries only show prefix and message.
+		s.Level = ""
+	case f.DisableTimestamp:
+	default:
+		s.Timestamp = colors.TimestampColor("[" + f.displayTimestamp(r.Time) + "]")
+	}
+	if r.Caller != nil {
+		caller := f.callerText(r.Caller)
+		if f.CallerFunction {
+			caller += " " + f.functionName(r.Caller)
+		}
+		s.Caller = colors.CallerColor(caller)
+	}
+	if r.ID != "" {
+		s.ID = colors.TimestampColor("#" + r.ID)
+	}
+	if f.isProgramOutput(r.Level) {
+		s.Timestamp, s.Level, s.Prefix, s.Caller, s.ID = "", "", "", "", ""
+	}
+	if f.SpacePaddingAuto && s.Message != "" {
+		s.Message = f.autoPadMessage(s, r.terminal, r.Prefix, len(r.Fields) > 0 || s.Caller != "", !r.measuring)
+	}
+	s.Message = colors.messageColor(r.Level)(s.Message)
+
+	fields := &bytes.Buffer{}
+	e.appendFields(fields, r, colors.fieldKeyColor(r.Level))
+	s.Fields = fields.String()
+
+	if layout == nil {
+		s.writeDefault(b)
+		return nil
+	}
+	return layout.Execute(b, s)
+}
+
+func (e *coloredEncoder) appendFields(b *bytes.Buffer, r *Record, keyColor func(string) string) {
+	f := e.f
+	if r.Verbose {
+		f.appendVerboseFields(b, r.Fields, keyColor)
+		return
+	}
+	inline := r.Fields
+	if f.InlineFieldLimit > 0 && len(inline) > f.InlineFieldLimit {
+		inline = inline[:f.InlineFieldLimit]
+	}
+	for _, field := range inline {
+		b.WriteByte(' ')
+		b.WriteString(e.fieldText(r, field, keyColor))
+	}
+	if spilled := r.Fields[len(inline):]; len(spilled) > 0 {
+		texts := make([]string, len(spilled))
+		for i, field := range spilled {
+			texts[i] = e.fieldText(r, field, keyColor)
+		}
+		f.appendSpilledFields(b, texts)
+	}
+	if r.measuring {
+		// Only the first line is measured, so the continuation lines below,
+		// which may consume dump tokens, are left out.
+		return
+	}
+	if f.ErrorTree {
+		for _, field := range r.Fields {
+			if err, ok := field.Value.(error); ok && hasMultipleCauses(err) {
+				f.appendErrorTree(b, err, 0, f.compiledColorScheme().ErrorLevelColor)
+			}
+		}
+	}
+	if f.ErrorDetails {
+		for _, field := range r.Fields {
+			err, ok := field.Value.(error)
+			if !ok || f.ErrorTree && hasMultipleCauses(err) || !hasErrorDetails(err) {
+				continue
+			}
+			if f.allowDump() {
+				f.appendErrorDetails(b, err, f.compiledColorScheme().ErrorDetailColor)
+			} else {
+				b.WriteString(f.lineEnding())
+				b.WriteString(indent(0))
+				b.WriteString(f.compiledColorScheme().TimestampColor("(dump suppressed)"))
+			}
+		}
+	}
+}
+
+// fieldText renders a single colored key=value pair.
+func (e *coloredEncoder) fieldText(r *Record, field Field, keyColor func(string) string) string {
+	f := e.f
+	colors := f.compiledColorScheme()
+	if err, ok := field.Value.(error); ok && f.ErrorTree && hasMultipleCauses(err) {
+		return fmt.Sprintf("%s=%s", keyColor(field.Key), colors.FieldValueColor(shortenValue(field, f.normalize(singleLine(err.Error())))))
+	}
+	if changed, ok := r.Changed[field.Key]; ok {
+		valueColor := colors.TimestampColor
+		if changed {
+			valueColor = changedValueColor
+		}
+		return fmt.Sprintf("%s=%s", keyColor(field.Key), valueColor(f.valueText(field)))
+	}
+	if containsString(f.CorrelationKeys, field.Key) {
+		// The color follows the full value, so it survives truncation.
+		color := f.hashColor(fmt.Sprintf("%+v", field.Value))
+		return fmt.Sprintf("%s=%s", keyColor(field.Key), color(f.valueText(field)))
+	}
+	return fmt.Sprintf("%s=%s", keyColor(field.Key), colors.FieldValueColor(f.valueText(field)))
+}
+
+// logfmtEncoder renders plain key=value pairs for non-terminal output.
+type logfmtEncoder struct {
+	f *TextFormatter
+}
+
+func (e *logfmtEncoder) Encode(b *bytes.Buffer, r *Record) error {
+	if e.f.isProgramOutput(r.Level) {
+		b.WriteString(r.Message)
+		if len(r.Fields) > 0 {
+			b.WriteByte(' ')
+		}
+		for _, field := range r.Fields {
+			e.appendField(b, field)
+		}
+		return nil
+	}
+	if !e.f.DisableTimestamp {
+		e.appendKeyValue(b, e.f.FieldMap.resolve(FieldKeyTime), e.f.plainTimestamp(r.Time))
+	}
+	e.appendKeyValue(b, e.f.FieldMap.resolve(FieldKeyLevel), e.f.levelName(r.Level))
+	if num, ok := e.f.levelNumber(r.Level); ok {
+		e.appendKeyValue(b, "level_num", num)
+	}
+	switch {
+	case r.messagePrefix != "" || r.Message != "":
+		e.appendKeyValue(b, e.f.FieldMap.resolve(FieldKeyMsg), r.messagePrefix+r.Message)
+	case e.f.EmptyMessage == EmptyMessageQuoted:
+		b.WriteString(e.f.FieldMap.resolve(FieldKeyMsg) + `="" `)
+	}
+	if r.Caller != nil {
+		e.appendKeyValue(b, "caller", e.f.callerText(r.Caller))
+		if e.f.CallerFunction {
+			e.appendKeyValue(b, "func", e.f.functionName(r.Caller))
+		}
+	}
+	if r.ID != "" {
+		e.appendKeyValue(b, "id", r.ID)
+	}
+	if r.Verbose && !e.f.StrictLogfmt {
+		e.f.appendVerboseFields(b, r.Fields, func(s string) string { return s })
+		return nil
+	}
+	for _, field := range r.Fields {
+		e.appendField(b, field)
+	}
+	return nil
+}
+
+func (e *logfmtEncoder) appendKeyValue(b *bytes.Buffer, key string, value interface{}) {
+	e.appendField(b, Field{Key: key, Value: value})
+}
+
+func (e *logfmtEncoder) appendField(b *bytes.Buffer, field Field) {
+	key, value := field.Key, field.Value
+	strict := e.f.StrictLogfmt
+	if strict {
+		key = logfmtKey(key)
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	if e.f.DisableQuoting && !strict {
+		b.WriteString(shortenValue(field, e.f.normalize(fmt.Sprint(value))))
+		b.WriteByte(' ')
+		return
+	}
+
+	var text string
+	var quote bool
+	switch value := value.(type) {
+	case string:
+		text, quote = value, needsQuoting(value, strict)
+	case error:
+		errmsg := value.Error()
+		text, quote = errmsg, needsQuoting(errmsg, strict)
+	default:
+		// Other values are written bare unless that would break the line
+		// into several pairs, e.g. slices or structs.
+		text = e.f.normalize(fmt.Sprint(value))
+		quote = breaksLogfmt(text)
+	}
+	text = shortenValue(field, text)
+	if quote {
+		b.WriteString(strconv.Quote(text))
+	} else {
+		b.WriteString(text)
+	}
+
+	b.WriteByte(' ')
+}
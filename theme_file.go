@@ -0,0 +1,83 @@
+package prefixed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LoadColorScheme reads a JSON encoded ColorScheme, e.g.
+// {"Name": "mine", "WarnLevelStyle": "yellow+b"}, from path and validates it.
+func LoadColorScheme(path string) (*ColorScheme, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	colorScheme := &ColorScheme{}
+	if err := json.NewDecoder(file).Decode(colorScheme); err != nil {
+		return nil, err
+	}
+	if err := colorScheme.Validate(); err != nil {
+		return nil, err
+	}
+	return colorScheme, nil
+}
+
+// WatchColorScheme applies the color scheme stored at path and reapplies it
+// whenever the file changes, checking every interval, so colors can be tuned
+// live while tailing output. Schemes are swapped atomically. When a changed
+// file fails to load, the current scheme is kept and the error is passed to
+// onError, which may be nil. Call stop to end watching. The interval must be
+// positive.
+func (f *TextFormatter) WatchColorScheme(path string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("non-positive interval %v for watching color scheme %s", interval, path)
+	}
+	colorScheme, err := LoadColorScheme(path)
+	if err != nil {
+		return nil, err
+	}
+	f.colorScheme.Store(compileColorScheme(colorScheme))
+
+	modTime := fileModTime(path)
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t := fileModTime(path)
+				if t.Equal(modTime) {
+					continue
+				}
+				modTime = t
+
+				colorScheme, err := LoadColorScheme(path)
+				if err != nil {
+					if onError != nil {
+						onError(err)
+					}
+					continue
+				}
+				f.colorScheme.Store(compileColorScheme(colorScheme))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+func fileModTime(path string) time.Time {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return stat.ModTime()
+}
@@ -0,0 +1,97 @@
+package prefixed
+
+import (
+	"strings"
+	"time"
+)
+
+// TimestampPrecision selects the sub-second digits of full timestamps.
+type TimestampPrecision int
+
+const (
+	// TimestampPrecisionLayout keeps the fractional seconds of the layout as
+	// they are. This is the default.
+	TimestampPrecisionLayout TimestampPrecision = iota
+	// TimestampPrecisionSeconds drops fractional seconds.
+	TimestampPrecisionSeconds
+	// TimestampPrecisionMillis renders three fractional digits.
+	TimestampPrecisionMillis
+	// TimestampPrecisionMicros renders six fractional digits.
+	TimestampPrecisionMicros
+	// TimestampPrecisionNanos renders nine fractional digits.
+	TimestampPrecisionNanos
+)
+
+var precisionFractions = map[TimestampPrecision]string{
+	TimestampPrecisionMillis: ".000",
+	TimestampPrecisionMicros: ".000000",
+	TimestampPrecisionNanos:  ".000000000",
+}
+
+// withPrecision replaces the fractional seconds following the seconds
+// element of layout with the digits of precision. Layouts without seconds
+// are returned unchanged.
+func withPrecision(layout string, precision TimestampPrecision) string {
+	if precision == TimestampPrecisionLayout {
+		return layout
+	}
+	i := strings.LastIndex(layout, "05")
+	if i < 0 {
+		return layout
+	}
+	i += len("05")
+
+	end, fraction := i, precisionFractions[precision]
+	if end < len(layout) && (layout[end] == '.' || layout[end] == ',') {
+		digits := end + 1
+		for digits < len(layout) && (layout[digits] == '0' || layout[digits] == '9') {
+			digits++
+		}
+		if digits > end+1 {
+			// Keep the decimal separator of the layout.
+			if fraction != "" {
+				fraction = layout[end:end+1] + fraction[1:]
+			}
+			end = digits
+		}
+	}
+	return layout[:i] + fraction + layout[end:]
+}
+
+// formatTimestamp renders the entry time t with layout, applying
+// TimestampUTC and TimestampPrecision.
+func (f *TextFormatter) formatTimestamp(t time.Time, layout string) string {
+	if f.TimestampUTC {
+		t = t.UTC()
+	}
+	return t.Format(withPrecision(layout, f.TimestampPrecision))
+}
+
+// EpochUnit selects the unit of numeric timestamps.
+type EpochUnit int
+
+const (
+	// EpochNone renders timestamps with the timestamp layout. This is the
+	// default.
+	EpochNone EpochUnit = iota
+	// EpochSeconds renders timestamps as Unix seconds.
+	EpochSeconds
+	// EpochMillis renders timestamps as Unix milliseconds.
+	EpochMillis
+	// EpochNanos renders timestamps as Unix nanoseconds.
+	EpochNanos
+)
+
+// plainTimestamp returns the time value of plain and JSON output: a number
+// in TimestampEpoch mode, otherwise the formatted timestamp.
+func (f *TextFormatter) plainTimestamp(t time.Time) interface{} {
+	switch f.TimestampEpoch {
+	case EpochSeconds:
+		return t.Unix()
+	case EpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	case EpochNanos:
+		return t.UnixNano()
+	}
+	return f.formatTimestamp(t, f.timestampFormat())
+}
@@ -0,0 +1,28 @@
+package prefixed
+
+import "sync/atomic"
+
+// SuppressColors switches a live formatter between its configured colors and
+// plain output, e.g. for operators copying clean text out of a terminal.
+func (f *TextFormatter) SuppressColors(suppress bool) {
+	var v int32
+	if suppress {
+		v = 1
+	}
+	atomic.StoreInt32(&f.colorsSuppressed, v)
+}
+
+// ToggleColors flips color suppression and reports whether colors are now
+// suppressed.
+func (f *TextFormatter) ToggleColors() bool {
+	for {
+		old := atomic.LoadInt32(&f.colorsSuppressed)
+		if atomic.CompareAndSwapInt32(&f.colorsSuppressed, old, 1-old) {
+			return old == 0
+		}
+	}
+}
+
+func (f *TextFormatter) colorsAreSuppressed() bool {
+	return atomic.LoadInt32(&f.colorsSuppressed) == 1
+}
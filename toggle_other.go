@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package prefixed
+
+// ToggleColorsOnSignal does nothing: SIGUSR2 only exists on Unix systems. Use
+// ToggleColors directly, e.g. from an admin endpoint. The returned stop
+// function does nothing either.
+func (f *TextFormatter) ToggleColorsOnSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,34 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package prefixed
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ToggleColorsOnSignal toggles color suppression every time the process
+// receives SIGUSR2, until the returned stop function is called.
+func (f *TextFormatter) ToggleColorsOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				f.ToggleColors()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
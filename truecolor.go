@@ -0,0 +1,178 @@
+package prefixed
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// Number of colors supported by the terminal.
+const (
+	colorDepth8    = 8
+	colorDepth256  = 256
+	colorDepthTrue = 1 << 24
+)
+
+// terminalColorDepth guesses the colors supported by the terminal from the
+// COLORTERM and TERM environment variables.
+func terminalColorDepth() int {
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return colorDepthTrue
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return colorDepth256
+	}
+	return colorDepth8
+}
+
+// styleColorFunc works like ansi.ColorFunc, but additionally accepts #rrggbb
+// colors. They are downgraded to the nearest color the terminal supports, as
+// are 256-color codes on basic terminals.
+func styleColorFunc(style string, depth int) func(string) string {
+	if depth >= colorDepth256 && !strings.Contains(style, "#") {
+		return ansi.ColorFunc(style)
+	}
+
+	parts := strings.Split(style, ":")
+	var trueColors []string
+	for i, part := range parts {
+		color, attributes := part, ""
+		if plus := strings.IndexByte(part, '+'); plus >= 0 {
+			color, attributes = part[:plus], part[plus:]
+		}
+		r, g, b, ok := parseHexColor(color)
+		if !ok {
+			n, err := strconv.Atoi(color)
+			if err != nil || n < 0 || n > 255 || depth >= colorDepth256 {
+				continue
+			}
+			if n < 16 {
+				// The first 16 codes are the basic colors and their bright
+				// variants.
+				if n >= 8 {
+					attributes = "+" + strings.TrimPrefix(attributes, "+") + "h"
+				}
+				parts[i] = basicColors[n%8].name + attributes
+				continue
+			}
+			r, g, b = paletteRGB(n)
+		}
+		switch depth {
+		case colorDepthTrue:
+			// ansi has no 24-bit syntax, so the color is appended after the
+			// code of the remaining style.
+			color = "default"
+			trueColors = append(trueColors, fmt.Sprintf("\033[%d;2;%d;%d;%dm", 38+10*i, r, g, b))
+		case colorDepth256:
+			color = strconv.Itoa(nearest256(r, g, b))
+		default:
+			color = nearest8(r, g, b)
+		}
+		parts[i] = color + attributes
+	}
+	style = strings.Join(parts, ":")
+	if len(trueColors) == 0 {
+		return ansi.ColorFunc(style)
+	}
+
+	code := ansi.ColorCode(style) + strings.Join(trueColors, "")
+	return func(s string) string {
+		if s == "" {
+			return s
+		}
+		return code + s + ansi.Reset
+	}
+}
+
+func parseHexColor(color string) (r, g, b int, ok bool) {
+	if len(color) != 7 || color[0] != '#' {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(n >> 16), int(n >> 8 & 0xff), int(n & 0xff), true
+}
+
+// Intensities of the 6x6x6 color cube of the 256-color palette.
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// nearest256 returns the 256-color palette index closest to r, g, b out of
+// the color cube and the grayscale ramp.
+func nearest256(r, g, b int) int {
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	best := 16 + 36*ri + 6*gi + bi
+	bestDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	for i := 0; i < 24; i++ {
+		gray := 8 + 10*i
+		if dist := colorDistance(r, g, b, gray, gray, gray); dist < bestDist {
+			best, bestDist = 232+i, dist
+		}
+	}
+	return best
+}
+
+// paletteRGB returns the approximate color of the 256-color palette index n,
+// out of the color cube and the grayscale ramp.
+func paletteRGB(n int) (r, g, b int) {
+	if n >= 232 {
+		gray := 8 + 10*(n-232)
+		return gray, gray, gray
+	}
+	n -= 16
+	return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+}
+
+func nearestLevel(v int) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(v-level) < abs(v-cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Approximate values of the basic colors in common terminals.
+var basicColors = []struct {
+	name    string
+	r, g, b int
+}{
+	{"black", 0, 0, 0},
+	{"red", 205, 0, 0},
+	{"green", 0, 205, 0},
+	{"yellow", 205, 205, 0},
+	{"blue", 0, 0, 238},
+	{"magenta", 205, 0, 205},
+	{"cyan", 0, 205, 205},
+	{"white", 229, 229, 229},
+}
+
+// nearest8 returns the name of the basic color closest to r, g, b.
+func nearest8(r, g, b int) string {
+	best := basicColors[0]
+	for _, c := range basicColors[1:] {
+		if colorDistance(r, g, b, c.r, c.g, c.b) < colorDistance(r, g, b, best.r, best.g, best.b) {
+			best = c
+		}
+	}
+	return best.name
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
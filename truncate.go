@@ -0,0 +1,153 @@
+package prefixed
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mgutz/ansi"
+)
+
+// TruncationTarget is a part of an entry that may be shortened to respect
+// MaxLineLength.
+type TruncationTarget int
+
+const (
+	// TruncateFields drops fields, starting with the last printed one.
+	TruncateFields TruncationTarget = iota
+	// TruncateValues shortens the rendered text of long field values. Custom
+	// encoders render values unshortened.
+	TruncateValues
+	// TruncateMessage shortens the message.
+	TruncateMessage
+)
+
+var defaultTruncationOrder = []TruncationTarget{TruncateFields, TruncateValues, TruncateMessage}
+
+// Field values are not shortened below this number of characters.
+const minTruncatedValueLength = 8
+
+// fitRecord shortens r in TruncationOrder until the first line enc renders
+// is within MaxLineLength visible characters. Trial renders are flagged as
+// measuring, so the built-in encoders don't learn widths from candidates or
+// consume dump tokens. Field values keep their type: only their rendered
+// text is shortened.
+func (f *TextFormatter) fitRecord(enc Encoder, r *Record) error {
+	length := func() (int, error) {
+		var b bytes.Buffer
+		r.measuring = true
+		err := enc.Encode(&b, r)
+		r.measuring = false
+		line := b.String()
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = strings.TrimSuffix(line[:i], "\r")
+		}
+		return visibleWidth(line), err
+	}
+
+	n, err := length()
+	if err != nil || n <= f.MaxLineLength {
+		return err
+	}
+
+	order := f.TruncationOrder
+	if len(order) == 0 {
+		order = defaultTruncationOrder
+	}
+
+	for _, target := range order {
+		switch target {
+		case TruncateFields:
+			for len(r.Fields) > 0 && n > f.MaxLineLength {
+				r.Fields = r.Fields[:len(r.Fields)-1]
+				if n, err = length(); err != nil {
+					return err
+				}
+			}
+		case TruncateValues:
+			for i := len(r.Fields) - 1; i >= 0 && n > f.MaxLineLength; i-- {
+				width := visibleWidth(f.valueText(r.Fields[i]))
+				for n > f.MaxLineLength && width > minTruncatedValueLength {
+					width -= n - f.MaxLineLength
+					if width < minTruncatedValueLength {
+						width = minTruncatedValueLength
+					}
+					r.Fields[i].width = width
+					if n, err = length(); err != nil {
+						return err
+					}
+				}
+			}
+		case TruncateMessage:
+			if n > f.MaxLineLength {
+				width := visibleWidth(r.Message) - (n - f.MaxLineLength)
+				if width > 0 {
+					r.Message = truncateWidth(r.Message, width)
+				} else {
+					r.Message = ""
+				}
+				if n, err = length(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// shortenValue shortens text, the rendered value of field, to the width set
+// by MaxLineLength.
+func shortenValue(field Field, text string) string {
+	if field.width > 0 {
+		return truncateWidth(text, field.width)
+	}
+	return text
+}
+
+// truncateWidth shortens s to width terminal columns, marking the cut with an
+// ellipsis. ANSI escape sequences, kept with AllowANSI, take no columns and
+// are reset after the cut, so their styles don't bleed into what follows.
+func truncateWidth(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	escaped := false
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			end := ansiSequenceEnd([]byte(s), i) + 1
+			b.WriteString(s[i:end])
+			escaped = true
+			i = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if used+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+		i += size
+	}
+	b.WriteString("…")
+	if escaped {
+		b.WriteString(ansi.Reset)
+	}
+	return b.String()
+}
+
+// truncateMiddle shortens s to length characters by cutting out its middle,
+// preserving both ends, e.g. net…handler.
+func truncateMiddle(s string, length int) string {
+	runes := []rune(s)
+	if length <= 0 || len(runes) <= length {
+		return s
+	}
+	if length == 1 {
+		return "…"
+	}
+	head := (length - 1) / 2
+	tail := length - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
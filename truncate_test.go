@@ -0,0 +1,102 @@
+package prefixed
+
+import "testing"
+
+func TestTruncateWidth(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hell…"},
+		{"日本語テキスト", 7, "日本語…"},
+		{"日本語テキスト", 6, "日本…"},
+		{"\x1b[1mbold text\x1b[0m", 6, "\x1b[1mbold …\x1b[0m"},
+		{"\x1b[1mbold\x1b[0m", 4, "\x1b[1mbold\x1b[0m"},
+	} {
+		if got := truncateWidth(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	plain := func(max int, order ...TruncationTarget) *TextFormatter {
+		return &TextFormatter{DisableColors: true, DisableTimestamp: true, MaxLineLength: max, TruncationOrder: order}
+	}
+	colored := func(max int, order ...TruncationTarget) *TextFormatter {
+		return &TextFormatter{ForceColors: true, AllowANSI: true, DisableTimestamp: true, MaxLineLength: max, TruncationOrder: order}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		f       *TextFormatter
+		message string
+		query   string
+		want    string
+	}{
+		{
+			name:    "fields dropped",
+			f:       plain(50),
+			message: "[db] query finished slowly",
+			want:    "level=info msg=\"[db] query finished slowly\" \n",
+		},
+		{
+			name:    "fields dropped, then message",
+			f:       plain(30),
+			message: "[db] query finished slowly",
+			want:    "level=info msg=\"[db] query …\" \n",
+		},
+		{
+			name:    "multibyte message",
+			f:       plain(30),
+			message: "[db] 日本語のメッセージです",
+			want:    "level=info msg=\"[db] 日本語…\" \n",
+		},
+		{
+			name:    "values",
+			f:       plain(70, TruncateValues),
+			message: "[db] query finished slowly",
+			want:    "level=info msg=\"[db] query finished slowly\" query=\"select * …\" rows=3 \n",
+		},
+		{
+			name:    "values with multibyte message",
+			f:       plain(70, TruncateValues),
+			message: "[db] 日本語のメッセージです",
+			want:    "level=info msg=\"[db] 日本語のメッセージです\" query=\"select *…\" rows=3 \n",
+		},
+		{
+			name:    "colored message",
+			f:       colored(30, TruncateFields, TruncateMessage),
+			message: "[db] query finished slowly",
+			want:    " \x1b[0;32m   INFO\x1b[0m \x1b[0;36mdb:\x1b[0m query finished s…\n",
+		},
+		{
+			name:    "ANSI value",
+			f:       colored(50, TruncateValues),
+			message: "[db] done",
+			query:   "\x1b[33mselect\x1b[0m * from users where id = 1",
+			want:    " \x1b[0;32m   INFO\x1b[0m \x1b[0;36mdb:\x1b[0m done \x1b[0;32mquery\x1b[0m=\x1b[33mselect\x1b[0m * from users where…\x1b[0m\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"query": "select * from users where id = 1"}
+			if tt.query != "" {
+				data["query"] = tt.query
+			} else {
+				data["rows"] = 3
+			}
+			out, err := tt.f.format(&logEntry{level: InfoLevel, message: tt.message, data: data})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(out); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+			if n := visibleWidth(string(out[:len(out)-1])); n > tt.f.MaxLineLength {
+				t.Errorf("%d visible characters, want at most %d", n, tt.f.MaxLineLength)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package prefixed
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Key suffixes recognized by UnitConventions and the durations they denote.
+var durationSuffixes = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"_ns", time.Nanosecond},
+	{"_us", time.Microsecond},
+	{"_ms", time.Millisecond},
+	{"_sec", time.Second},
+	{"_seconds", time.Second},
+}
+
+// applyUnitConventions renders numeric values of unit-suffixed keys with a
+// human-readable unit, e.g. latency_ms=1532 as 1.532s.
+func applyUnitConventions(key string, value interface{}) interface{} {
+	n, ok := toFloat(value)
+	if !ok {
+		return value
+	}
+
+	for _, d := range durationSuffixes {
+		if strings.HasSuffix(key, d.suffix) {
+			return time.Duration(n * float64(d.unit)).String()
+		}
+	}
+	switch {
+	case strings.HasSuffix(key, "_bytes"):
+		return humanBytes(n)
+	case strings.HasSuffix(key, "_pct"), strings.HasSuffix(key, "_percent"):
+		return fmt.Sprintf("%g%%", n)
+	}
+	return value
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// byteUnits are the binary prefixes used by humanBytes.
+const byteUnits = "KMGTPE"
+
+func humanBytes(n float64) string {
+	const unit = 1024
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return fmt.Sprint(n)
+	}
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%gB", n)
+	}
+	exp := 0
+	for (n >= unit*unit || n <= -unit*unit) && exp < len(byteUnits)-1 {
+		n /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", n/unit, byteUnits[exp])
+}
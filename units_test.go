@@ -0,0 +1,30 @@
+package prefixed
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHumanBytes(t *testing.T) {
+	for _, tt := range []struct {
+		n    float64
+		want string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{-512, "-512B"},
+		{1536, "1.5KiB"},
+		{-1536, "-1.5KiB"},
+		{3 << 30, "3.0GiB"},
+		{1e21, "867.4EiB"},
+		{-1e21, "-867.4EiB"},
+		{1e30, "867361737988.4EiB"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	} {
+		if got := humanBytes(tt.n); got != tt.want {
+			t.Errorf("humanBytes(%g) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package prefixed
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Loggable is implemented by types controlling their own rendering. The
+// formatter renders the value returned by LogValue in place of the original
+// one, in every output mode. Sensitive types can return Redacted.
+type Loggable interface {
+	LogValue() interface{}
+}
+
+type redacted string
+
+func (r redacted) String() string {
+	return string(r)
+}
+
+// Redacted is rendered in place of sensitive values.
+const Redacted = redacted("[REDACTED]")
+
+// Resolvers turning self-describing values into the values to render, e.g.
+// for slog.LogValuer when built with Go 1.21 or later.
+var valueResolvers = []func(value interface{}) (interface{}, bool){
+	func(value interface{}) (interface{}, bool) {
+		if l, ok := value.(Loggable); ok {
+			return l.LogValue(), true
+		}
+		return nil, false
+	},
+}
+
+// Maximum number of LogValue indirections followed, guarding against values
+// resolving to themselves.
+const maxResolveDepth = 10
+
+func resolveValue(value interface{}) interface{} {
+	for i := 0; i < maxResolveDepth; i++ {
+		resolved := false
+		for _, resolve := range valueResolvers {
+			if v, ok := resolve(value); ok {
+				value, resolved = v, true
+				break
+			}
+		}
+		if !resolved {
+			break
+		}
+	}
+	return value
+}
+
+var (
+	valueFormattersMu sync.RWMutex
+	valueFormatters   = map[reflect.Type]func(interface{}) string{}
+)
+
+// RegisterValueFormatter renders field values of type t with format in every
+// output mode, e.g. for time.Duration, net.IP or domain types, so callers
+// don't need to stringify them. Registering nil removes the formatter of t.
+func RegisterValueFormatter(t reflect.Type, format func(interface{}) string) {
+	valueFormattersMu.Lock()
+	defer valueFormattersMu.Unlock()
+	if format == nil {
+		delete(valueFormatters, t)
+		return
+	}
+	valueFormatters[t] = format
+}
+
+// formatValue applies the formatter registered for the type of value, if any.
+func formatValue(value interface{}) interface{} {
+	if value == nil {
+		return value
+	}
+	valueFormattersMu.RLock()
+	format, ok := valueFormatters[reflect.TypeOf(value)]
+	valueFormattersMu.RUnlock()
+	if !ok {
+		return value
+	}
+	return format(value)
+}
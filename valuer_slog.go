@@ -0,0 +1,15 @@
+//go:build go1.21
+// +build go1.21
+
+package prefixed
+
+import "log/slog"
+
+func init() {
+	valueResolvers = append(valueResolvers, func(value interface{}) (interface{}, bool) {
+		if l, ok := value.(slog.LogValuer); ok {
+			return l.LogValue().Resolve().Any(), true
+		}
+		return nil, false
+	})
+}
@@ -0,0 +1,55 @@
+package prefixed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// VerboseKey is a reserved field switching a single entry to a multi-line
+// layout with one pretty-printed field per line, e.g. to dump a config struct
+// once at startup:
+//
+//	log.WithField(prefixed.VerboseKey, true).WithField("config", cfg).Info("Starting")
+const VerboseKey = "@verbose"
+
+// isVerbose tells whether data requests the verbose layout.
+func isVerbose(data map[string]interface{}) bool {
+	v, ok := data[VerboseKey]
+	return ok && v != false
+}
+
+// appendVerboseFields writes each field on its own indented continuation
+// line.
+func (f *TextFormatter) appendVerboseFields(b *bytes.Buffer, fields []Field, keyColor func(string) string) {
+	for _, field := range fields {
+		b.WriteString(f.lineEnding())
+		b.WriteString(indent(0))
+		b.WriteString(keyColor(field.Key + ":"))
+		b.WriteByte(' ')
+		b.WriteString(strings.Replace(f.normalize(prettyValue(field.Value)), "\n", f.lineEnding()+indent(0), -1))
+	}
+}
+
+// prettyValue renders composite values as indented JSON and everything else
+// like in the single-line layout.
+func prettyValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if b, err := json.MarshalIndent(value, "", "  "); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%+v", value)
+}
@@ -0,0 +1,56 @@
+package prefixed
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// appendWatermark writes a dim marker line such as
+// "――― 12:00:00, 10k lines ―――" if one is due before the current entry.
+func (f *TextFormatter) appendWatermark(b *bytes.Buffer) {
+	if f.WatermarkEvery <= 0 && f.WatermarkInterval <= 0 {
+		return
+	}
+
+	n := atomic.AddUint64(&f.watermarkEntries, 1)
+	due := f.WatermarkEvery > 0 && n > 1 && (n-1)%uint64(f.WatermarkEvery) == 0
+
+	if f.WatermarkInterval > 0 {
+		now := f.now().UnixNano()
+		last := atomic.LoadInt64(&f.watermarkTime)
+		switch {
+		case last == 0:
+			atomic.CompareAndSwapInt64(&f.watermarkTime, 0, now)
+		case now-last >= int64(f.WatermarkInterval):
+			// Only one of concurrent entries gets the marker.
+			if atomic.CompareAndSwapInt64(&f.watermarkTime, last, now) {
+				due = true
+			}
+		}
+	}
+	if !due {
+		return
+	}
+
+	marker := fmt.Sprintf("――― %s, %s lines ―――", f.now().Format("15:04:05"), humanCount(n-1))
+	b.WriteString(f.compiledColorScheme().TimestampColor(marker))
+	b.WriteString(f.lineEnding())
+}
+
+// humanCount abbreviates large counts, e.g. 10k or 1.5M.
+func humanCount(n uint64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 1000000:
+		return trimZeroDecimal(fmt.Sprintf("%.1f", float64(n)/1e3)) + "k"
+	default:
+		return trimZeroDecimal(fmt.Sprintf("%.1f", float64(n)/1e6)) + "M"
+	}
+}
+
+func trimZeroDecimal(s string) string {
+	return strings.TrimSuffix(s, ".0")
+}
@@ -0,0 +1,63 @@
+package prefixed
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Width used for wrapping when it can't be detected.
+const defaultWrapWidth = 80
+
+// terminalWidth returns the width of the terminal w writes to, falling back
+// to the COLUMNS environment variable and then to defaultWrapWidth.
+func terminalWidth(w io.Writer) int {
+	if w != nil {
+		if width := ttyWidth(w); width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultWrapWidth
+}
+
+// wrapWidth returns the width colored output to t is wrapped at.
+func (f *TextFormatter) wrapWidth(t *terminal) int {
+	if f.WrapWidth > 0 {
+		return f.WrapWidth
+	}
+	return t.currentWidth()
+}
+
+// wrapLines soft-wraps each line of s at width visible columns, breaking at
+// spaces and indenting continuation lines. Escape sequences don't count
+// towards the width, and colors carry over to continuation lines.
+func (f *TextFormatter) wrapLines(s string, width int) string {
+	continuation := indent(0)
+	var b strings.Builder
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		column := 0
+		for j, word := range strings.Split(line, " ") {
+			wordWidth := visibleWidth(word)
+			if j > 0 {
+				if column > len(continuation) && column+1+wordWidth > width {
+					b.WriteString(f.lineEnding())
+					b.WriteString(continuation)
+					column = len(continuation)
+				} else {
+					b.WriteByte(' ')
+					column++
+				}
+			}
+			b.WriteString(word)
+			column += wordWidth
+		}
+	}
+	return b.String()
+}